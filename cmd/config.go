@@ -0,0 +1,196 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/imports"
+)
+
+// configFileName is the name of the config files found in the directories of
+// the organized files and their parents.
+const configFileName = ".openshift-goimports.yaml"
+
+// globalConfigFile is the absolute path of the config file given by --config
+// or found in the home directory, empty when there is none.
+var globalConfigFile string
+
+// configFlags are the flags overriding the keys of the config files.
+var configFlags = map[string]string{
+	"module":        "module",
+	"aliasTemplate": "alias-template",
+	"splitBy":       "split-by",
+	"blankImports":  "blank-imports",
+	"dotImports":    "dot-imports",
+	"sort":          "sort",
+}
+
+// bindFlags binds the flags to their keys in v, so that they take precedence
+// over the config files.
+func bindFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	for key, flag := range configFlags {
+		v.BindPFlag(key, flags.Lookup(flag))
+	}
+}
+
+// findConfigFiles returns the config files of the directory of path and of
+// its parents, up to the root of the git repository holding it, from the
+// farthest to the nearest. The global config file is left out.
+func findConfigFiles(path string) ([]string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if s, err := os.Stat(dir); err == nil && !s.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	var found []string
+	for {
+		config := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(config); err == nil && config != globalConfigFile {
+			found = append([]string{config}, found...)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return found, nil
+}
+
+// readConfigFiles returns the config merging configs over the global config
+// file, from the farthest to the nearest, the flags of cmd taking precedence.
+func readConfigFiles(cmd *cobra.Command, configs []string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetEnvPrefix("OPENSHIFT_GOIMPORTS")
+	v.AutomaticEnv()
+	if globalConfigFile != "" {
+		configs = append([]string{globalConfigFile}, configs...)
+	}
+	for _, config := range configs {
+		v.SetConfigFile(config)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("unable to read config file %s: %v", config, err)
+		}
+		klog.V(2).Infof("Using config file: %s", config)
+	}
+	bindFlags(v, cmd.Root().PersistentFlags())
+	return v, nil
+}
+
+// configFor returns the config of the files under path, from the config
+// files found for path merged over the global one.
+func configFor(cmd *cobra.Command, path string) *viper.Viper {
+	configs, err := findConfigFiles(path)
+	if err == nil {
+		var v *viper.Viper
+		if v, err = readConfigFiles(cmd, configs); err == nil {
+			return v
+		}
+	}
+	klog.Errorf("%v", err)
+	os.Exit(1)
+	return nil
+}
+
+// dirConfigs gives the options of the files of each directory, from the
+// config files found in it and its parents merged over the global one, the
+// flags taking precedence.
+type dirConfigs struct {
+	cmd  *cobra.Command
+	base *imports.Options
+
+	mu      sync.Mutex
+	dirs    map[string]*imports.Options
+	configs map[string]*imports.Options
+	errs    map[string]error
+}
+
+func newDirConfigs(cmd *cobra.Command, base *imports.Options) *dirConfigs {
+	return &dirConfigs{
+		cmd:     cmd,
+		base:    base,
+		dirs:    map[string]*imports.Options{},
+		configs: map[string]*imports.Options{},
+		errs:    map[string]error{},
+	}
+}
+
+// forDir returns the options of the files of dir. Directories with the same
+// config files share their options, and the ones without any use the options
+// of the command. The error of invalid config files is returned for every
+// directory using them, loading them only once.
+func (c *dirConfigs) forDir(dir string) (*imports.Options, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if opts, ok := c.dirs[dir]; ok {
+		return opts, nil
+	}
+
+	configs, err := findConfigFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join(configs, string(filepath.ListSeparator))
+	if err, ok := c.errs[key]; ok {
+		return nil, err
+	}
+	opts, ok := c.configs[key]
+	if len(configs) == 0 {
+		opts, ok = c.base, true
+	}
+	if !ok {
+		if opts, err = c.load(configs); err != nil {
+			c.errs[key] = err
+			return nil, err
+		}
+		c.configs[key] = opts
+	}
+	c.dirs[dir] = opts
+	return opts, nil
+}
+
+// load returns the options resulting from the config files, the nearest one
+// deciding the go.mod file used.
+func (c *dirConfigs) load(configs []string) (*imports.Options, error) {
+	v, err := readConfigFiles(c.cmd, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := *c.base
+	opts.ForDir = nil
+	if err := loadConfig(v, c.cmd, filepath.Dir(configs[len(configs)-1]), &opts); err != nil {
+		return nil, fmt.Errorf("%s: %v", configs[len(configs)-1], err)
+	}
+	return &opts, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/imports"
+)
+
+func TestDirConfigsPrecedence(t *testing.T) {
+	home, repo := t.TempDir(), t.TempDir()
+	for name, content := range map[string]string{
+		filepath.Join(home, ".openshift-goimports.yaml"): "intermediates:\n- github.com/thirdy\n",
+		filepath.Join(repo, "go.mod"):                    "module example.com/repo\n",
+		filepath.Join(repo, configFileName):              "deny:\n- path: os\n  reason: root\n",
+		filepath.Join(repo, "sub", configFileName):       "deny:\n- path: fmt\n  reason: sub\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(global string) { globalConfigFile = global }(globalConfigFile)
+	globalConfigFile = filepath.Join(home, ".openshift-goimports.yaml")
+
+	tests := []struct {
+		name string
+		dir  string
+		want []imports.DenyRule
+	}{
+		{
+			name: "root config over the global one",
+			dir:  repo,
+			want: []imports.DenyRule{{Path: "os", Reason: "root"}},
+		},
+		{
+			name: "nearest config over its parent",
+			dir:  filepath.Join(repo, "sub"),
+			want: []imports.DenyRule{{Path: "fmt", Reason: "sub"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := loadOptions(rootCmd, tt.dir)
+			opts, err := newDirConfigs(rootCmd, base).forDir(tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range []*imports.Options{base, opts} {
+				if !reflect.DeepEqual(o.Deny, tt.want) {
+					t.Errorf("got deny rules %v, want %v", o.Deny, tt.want)
+				}
+				if want := []string{"github.com/thirdy"}; !reflect.DeepEqual(o.Intermediates, want) {
+					t.Errorf("got intermediates %v, want %v", o.Intermediates, want)
+				}
+				if o.Module != "example.com/repo" {
+					t.Errorf("got module %q, want example.com/repo", o.Module)
+				}
+			}
+		})
+	}
+}
+
+func TestDirConfigsInvalid(t *testing.T) {
+	repo := t.TempDir()
+	for name, content := range map[string]string{
+		filepath.Join(repo, "go.mod"):                    "module example.com/repo\n",
+		filepath.Join(repo, "sub", configFileName):       "intermediates:\n- github.com/(foo\n",
+		filepath.Join(repo, "sub", "deeper", "keep.txt"): "",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(global string) { globalConfigFile = global }(globalConfigFile)
+	globalConfigFile = ""
+
+	configs := newDirConfigs(rootCmd, loadOptions(rootCmd, repo))
+	for _, dir := range []string{filepath.Join(repo, "sub"), filepath.Join(repo, "sub", "deeper")} {
+		if _, err := configs.forDir(dir); err == nil {
+			t.Errorf("wanted the invalid config of %s to be reported", dir)
+		}
+	}
+	if _, err := configs.forDir(repo); err != nil {
+		t.Errorf("wanted the options of %s, got %v", repo, err)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/imports"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain <import-path>...",
+	Short: "Explain which group each import path is put in and why.",
+	Long: `Explain prints, for each import path, the group it is put in, the pattern
+that won, and every other pattern that also matched in order of precedence.
+The imports are classified as in the go file at --path, or as in the non-test
+files of the directory at --path, with the module, intermediates, config
+files and overrides that apply to it. An import path may be preceded by a
+name and a space, such as "_ embed", to explain where blank and dot imports
+are placed.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(path) == 0 {
+			path = "."
+		}
+		opts := loadOptions(cmd, path)
+		opts.ForDir = newDirConfigs(cmd, opts).forDir
+
+		explanations, err := imports.Explain(path, args, opts)
+		if err != nil {
+			klog.Error(err)
+			os.Exit(1)
+		}
+		for _, e := range explanations {
+			if e.Name != "" {
+				fmt.Printf("%s %s\n", e.Name, e.Path)
+			} else {
+				fmt.Printf("%s\n", e.Path)
+			}
+			fmt.Printf("  group:   %s\n", e.Group)
+			if e.Placed {
+				fmt.Printf("  placed:  in the %s group by its placement\n", e.Group)
+			}
+			if len(e.Matches) == 0 {
+				if !e.Placed {
+					fmt.Printf("  matched: nothing, imports matching no other group are standard\n")
+				}
+				continue
+			}
+			fmt.Printf("  matched: %s (pattern %s)\n", e.Matches[0].Group, e.Matches[0].Pattern)
+			for _, m := range e.Matches[1:] {
+				fmt.Printf("  also:    %s (pattern %s)\n", m.Group, m.Pattern)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/imports"
+)
+
+// rewriteCmd represents the rewrite command
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite old=new...",
+	Short: "Move imports of packages to other import paths.",
+	Long: `Rewrite moves the imports of each old package, and of the packages under
+it, to the new import path, in the go files under --path. When several
+mappings match an import, the longest old path wins. An import whose package
+name changes keeps its old name as an alias. The imports of the changed files
+are organized in the same pass.`,
+	Example: `  openshift-goimports rewrite k8s.io/utils/pointer=k8s.io/utils/ptr
+  openshift-goimports rewrite github.com/example/old=github.com/example/new -p ./pkg`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(path) == 0 {
+			path = "."
+		}
+
+		opts := loadOptions(cmd, path)
+		for _, arg := range args {
+			r, err := imports.ParseRewrite(arg)
+			if err != nil {
+				klog.Error(err)
+				os.Exit(1)
+			}
+			opts.Rewrites = append(opts.Rewrites, r)
+		}
+		opts.Reporter = imports.NewReporter(os.Stdout, imports.OutputText)
+		opts.Progress = &imports.Progress{}
+		opts.ForDir = newDirConfigs(cmd, opts).forDir
+
+		ctx := signalContext()
+		files := newQueue()
+		var wg sync.WaitGroup
+		for i := 0; i < cap(files); i++ {
+			wg.Add(1)
+			go imports.FormatWithContext(ctx, files, &wg, opts)
+		}
+		queueFiles(ctx, cmd, path, files, &wg)
+		wg.Wait()
+		exitIfInterrupted(ctx, opts.Progress)
+
+		if opts.Reporter.Count() > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rewriteCmd)
+}
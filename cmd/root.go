@@ -17,19 +17,25 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strings"
 	"sync"
+	"syscall"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"golang.org/x/mod/modfile"
+	gomodule "golang.org/x/mod/module"
 
 	klog "k8s.io/klog/v2"
 
@@ -39,15 +45,27 @@ import (
 
 var (
 	intermediatesList []string
+	excludes          []string
+	includes          []string
+	includeGenerated  bool
 	module            string
 	path              string
 	dry               bool
 	list              bool
+	output            string
+	fixAliases        bool
+	removeAliases     bool
+	fixImports        bool
+	aliasConflicts    bool
+	migrateIoutil     bool
+	checkRestrictions bool
+	preserveSubgroups bool
+	splitGroups       []string
 	cfgFile           string
 	wg                sync.WaitGroup
 	impLine           = regexp.MustCompile(`^\s+(?:[\w\.]+\s+)?"(.+)"`)
 	vendor            = regexp.MustCompile(`vendor/`)
-	files             = make(chan string, 1000)
+	jobs              int
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -55,6 +73,7 @@ var rootCmd = &cobra.Command{
 	Use:   "openshift-goimports",
 	Short: "Organize go imports according to OpenShift best practices.",
 	Long:  ``,
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		switch {
 		case len(args) > 1:
@@ -70,63 +89,117 @@ var rootCmd = &cobra.Command{
 			path = "."
 		}
 
-		// If no module is provided, let's try to determine it programatically
-		if len(module) == 0 {
-			module, err := findGoModule(path)
-			if err != nil {
-				klog.Errorf("no module name provided and failed to find go.mod: %v", err)
-				os.Exit(1)
-			}
-			if module == "" {
-				klog.Error("unable to automatically determine module path, please provide one using the --module flag")
-				os.Exit(1)
-			}
+		if !imports.IsOutput(output) {
+			klog.Errorf("unknown output %q, must be one of %s", output, strings.Join(imports.Outputs, ", "))
+			os.Exit(1)
 		}
 
-		klog.V(2).Infof("Using module path %q", module)
+		opts := loadOptions(cmd, path)
+		opts.Dry = dry
+		opts.List = list
+		opts.Output = output
+		opts.FixAliases = fixAliases
+		opts.RemoveRedundantAliases = removeAliases
+		opts.FixImports = fixImports
+		opts.AliasConflicts = aliasConflicts
+		opts.MigrateIoutil = migrateIoutil
+		opts.CheckRestrictions = checkRestrictions
+		opts.PreserveSubgroups = preserveSubgroups
+		opts.Reporter = imports.NewReporter(os.Stdout, output)
+		opts.Progress = &imports.Progress{}
+		opts.ForDir = newDirConfigs(cmd, opts).forDir
 
-		for i := 0; i < 10; i++ {
+		ctx := signalContext()
+		files := newQueue()
+		for i := 0; i < cap(files); i++ {
 			wg.Add(1)
-			go imports.Format(files, &wg, intermediatesList, &module, &dry, &list)
+			go imports.FormatWithContext(ctx, files, &wg, opts)
 		}
 
-		if s, err := os.Stat(path); err != nil {
-			klog.Errorf("unable to stat path %q: %v", path, err)
-			os.Exit(1)
-		} else if s.IsDir() {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-
-				err := filepath.Walk(path,
-					func(path string, f os.FileInfo, err error) error {
-						if err != nil {
-							return err
-						}
-						if f.IsDir() && f.Name() == "vendor" {
-							return filepath.SkipDir
-						}
-						if util.IsGoFile(f) {
-							klog.V(2).Infof("Queueing %s", path)
-							files <- path
-						}
-						return nil
-					})
-				if err != nil {
-					klog.Error(err)
-				}
-				close(files)
-			}()
-		} else {
-			klog.V(2).Infof("Queueing %s", path)
-			files <- path
-			close(files)
-		}
+		queueFiles(ctx, cmd, path, files, &wg)
 
 		wg.Wait()
+		exitIfInterrupted(ctx, opts.Progress)
+
+		if opts.Reporter.Count() > 0 {
+			os.Exit(1)
+		}
 	},
 }
 
+// newQueue returns the channel the files to process are sent to, buffered
+// for each of the --jobs workers only, so that the walk streams the paths.
+func newQueue() chan string {
+	workers := jobs
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers < 0 {
+		klog.Errorf("invalid number of jobs %d, must be positive", jobs)
+		os.Exit(1)
+	}
+	return make(chan string, workers)
+}
+
+// queueFiles sends path, or the go files under it when it is a directory, to
+// files, skipping the paths excluded by the flags, the config file or the
+// .gitignore files, and stopping when ctx is done.
+func queueFiles(ctx context.Context, cmd *cobra.Command, path string, files chan<- string, wg *sync.WaitGroup) {
+	if s, err := os.Stat(path); err != nil {
+		klog.Errorf("unable to stat path %q: %v", path, err)
+		os.Exit(1)
+	} else if s.IsDir() {
+		v := configFor(cmd, path)
+		walker, err := util.NewWalker(path, append(excludes, v.GetStringSlice("exclude")...), append(includes, v.GetStringSlice("include")...))
+		if err != nil {
+			klog.Errorf("invalid exclude or include pattern: %v", err)
+			os.Exit(1)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := walker.Walk(ctx, func(path string) {
+				klog.V(2).Infof("Queueing %s", path)
+				files <- path
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				klog.Error(err)
+			}
+			close(files)
+		}()
+	} else {
+		klog.V(2).Infof("Queueing %s", path)
+		files <- path
+		close(files)
+	}
+}
+
+// signalContext returns a context cancelled on the first SIGINT or SIGTERM,
+// the next ones terminating the process as usual.
+func signalContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx
+}
+
+// exitIfInterrupted prints how many files were processed and the queued ones
+// left out when ctx was cancelled, and exits.
+func exitIfInterrupted(ctx context.Context, progress *imports.Progress) {
+	if ctx.Err() == nil {
+		return
+	}
+	left := progress.Left()
+	klog.Warningf("Interrupted after processing %d files and skipping %d, leaving out %d queued files and the files not walked yet", progress.Processed(), progress.Skipped(), len(left))
+	for _, path := range left {
+		klog.Warningf("Left out %s", path)
+	}
+	os.Exit(1)
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		klog.Error(err)
@@ -137,15 +210,38 @@ func Execute() {
 func init() {
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
-	cobra.OnInitialize()
+	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.openshift-goimports.yaml)")
 
-	rootCmd.Flags().StringVarP(&path, "path", "p", "", "The path to the go module to organize. Defaults to the current directory.")
-	rootCmd.Flags().StringArrayVarP(&intermediatesList, "intermediate", "i", []string{}, "Names of go modules to put between openshift and module to organize. Example usage: -i github.com/thirdy/one -i thirdy.io/two")
-	rootCmd.Flags().StringVarP(&module, "module", "m", "", "The name of the go module. Example: github.com/example-org/example-repo")
+	rootCmd.PersistentFlags().StringVarP(&path, "path", "p", "", "The path to the go module to organize. Defaults to the current directory.")
+	rootCmd.PersistentFlags().StringArrayVarP(&intermediatesList, "intermediate", "i", []string{}, "Names of go modules to put between openshift and module to organize. Example usage: -i github.com/thirdy/one -i thirdy.io/two")
+	rootCmd.PersistentFlags().StringVarP(&module, "module", "m", "", "The name of the go module. Example: github.com/example-org/example-repo")
+	rootCmd.PersistentFlags().StringArrayVar(&excludes, "exclude", []string{}, "Patterns of the paths to skip, in the .gitignore syntax and relative to the path. Example usage: --exclude 'zz_generated.*.go' --exclude /test/e2e/")
+	rootCmd.PersistentFlags().BoolVar(&includeGenerated, "include-generated", false, "Organize the files with a generated code header, such as // Code generated by deepcopy-gen. DO NOT EDIT., which are skipped by default")
+	rootCmd.PersistentFlags().StringArrayVar(&includes, "include", []string{}, "Patterns of the only go files to organize, in the .gitignore syntax and relative to the path. Example usage: --include 'pkg/**'")
+
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 0, "The number of files to process in parallel. Defaults to GOMAXPROCS, the number of CPUs available")
+
+	rootCmd.PersistentFlags().String("split-by", "", "Split groups into sub-groups separated by blank lines, one of: module, organization. Modules are resolved from the requirements of the go.mod file, and organizations are the hosts of the import paths followed by the organization for hosts such as github.com")
+	rootCmd.PersistentFlags().StringArrayVar(&splitGroups, "split-group", []string{}, "Names of the groups to split with --split-by, or patterns of intermediate ones. Defaults to the other group. Example usage: --split-group other --split-group kubernetes")
+	rootCmd.PersistentFlags().String("blank-imports", imports.PlacementSorted, "Where to put the blank imports, one of: sorted, first, last, group. first and last put them in a sub-group before or after the other imports of their group, and group puts them in their own group after the other groups")
+	rootCmd.PersistentFlags().String("dot-imports", imports.PlacementSorted, "Where to put the dot imports, one of: sorted, first, last, group")
+	rootCmd.PersistentFlags().String("sort", imports.SortLexical, "How to sort the imports of a group, one of: lexical, natural. natural compares the elements of the import paths one by one, versions such as v2 and v10 and other numbers by value, and letters regardless of case")
+
 	rootCmd.Flags().BoolVarP(&list, "list", "l", false, "List files whose imports are not sorted without making changes")
 	rootCmd.Flags().BoolVarP(&dry, "dry", "d", false, "Dry run only, do not actually make any changes to files")
+	rootCmd.Flags().BoolVar(&fixAliases, "fix-aliases", false, "Rename imports not using the alias required by the aliases of the config file instead of reporting them")
+	rootCmd.Flags().BoolVar(&removeAliases, "remove-redundant-aliases", false, "Remove import aliases that are the same as the name of the imported package")
+	rootCmd.Flags().BoolVar(&fixImports, "fix-imports", false, "Remove the unused imports and add the missing ones, looking for the packages in the module, its vendor directory and the standard library")
+	rootCmd.Flags().BoolVar(&aliasConflicts, "alias-conflicts", false, "Alias the imports whose names in the file are the same, using the alias template")
+	rootCmd.PersistentFlags().String("alias-template", imports.DefaultAliasTemplate, "Template of the aliases of conflicting imports, where {name} is the package name, and {parent} and {grandparent} are the elements of the import path before the package directory")
+	rootCmd.Flags().BoolVar(&migrateIoutil, "migrate-ioutil", false, "Replace the uses of the deprecated io/ioutil package with their os and io equivalents")
+	rootCmd.Flags().BoolVar(&checkRestrictions, "check-restrictions", false, "Report the imports forbidden by the .import-restrictions files of the directory of each file or the directories above it, up to the module")
+	rootCmd.Flags().BoolVar(&preserveSubgroups, "preserve-subgroups", false, "Keep the runs of imports separated by blank lines whose imports all belong to the same group as sub-groups of that group, only sorting the imports of each")
+	rootCmd.Flags().StringVarP(&output, "output", "o", imports.OutputText, "How to report files whose imports are not sorted, one of: text, github. The github output prints GitHub Actions annotations and does not make any changes to files")
+
+	bindFlags(viper.GetViper(), rootCmd.PersistentFlags())
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -166,15 +262,105 @@ func initConfig() {
 		viper.SetConfigName(".openshift-goimports")
 	}
 
+	viper.SetEnvPrefix("OPENSHIFT_GOIMPORTS")
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		klog.Infof("Using config file: %s", viper.ConfigFileUsed())
+		globalConfigFile, _ = filepath.Abs(viper.ConfigFileUsed())
 	}
 }
 
-func findGoModule(path string) (string, error) {
+// loadOptions returns the options to organize the imports under path, from
+// the flags, the config files found for path, and the global config file.
+func loadOptions(cmd *cobra.Command, path string) *imports.Options {
+	opts := &imports.Options{}
+	if err := loadConfig(configFor(cmd, path), cmd, path, opts); err != nil {
+		klog.Errorf("%v", err)
+		os.Exit(1)
+	}
+	return opts
+}
+
+// loadConfig sets the options of opts coming from v, the flags bound to it
+// and the go.mod file of path, taking the module and intermediates from the
+// flags or the config file, and falling back to the go.mod file for the
+// module. It returns the first invalid setting found.
+func loadConfig(v *viper.Viper, cmd *cobra.Command, path string, opts *imports.Options) error {
+	module := v.GetString("module")
+
+	// If no module is provided, let's try to determine it programatically
+	if len(module) == 0 {
+		var err error
+		module, err = findGoModule(path)
+		if err != nil {
+			return fmt.Errorf("no module name provided and failed to find go.mod: %v", err)
+		}
+		if module == "" {
+			return errors.New("unable to automatically determine module path, please provide one using the --module flag")
+		}
+	}
+
+	klog.V(2).Infof("Using module path %q for %s", module, path)
+
+	// viper does not decode string array flags, so only use the config file
+	// when no intermediate was given on the command line
+	intermediates := intermediatesList
+	if !cmd.Flags().Changed("intermediate") {
+		intermediates = v.GetStringSlice("intermediates")
+	}
+
+	opts.Module = module
+	opts.Intermediates = intermediates
+	opts.Requirements = findRequirements(path)
+	opts.IncludeGenerated = includeGenerated
+	opts.ModuleDir = ""
+	if modFilePath, err := findGoModFile(path); err == nil && modFilePath != "" {
+		opts.ModuleDir = filepath.Dir(modFilePath)
+	}
+	opts.AliasTemplate = v.GetString("aliasTemplate")
+	opts.SplitBy = v.GetString("splitBy")
+	if opts.SplitBy != "" && !imports.IsSplitMode(opts.SplitBy) {
+		return fmt.Errorf("unknown split mode %q, must be one of %s", opts.SplitBy, strings.Join(imports.SplitModes, ", "))
+	}
+	opts.SplitGroups = splitGroups
+	for key, placement := range map[string]*string{"blankImports": &opts.BlankImports, "dotImports": &opts.DotImports} {
+		*placement = v.GetString(key)
+		if *placement != "" && !imports.IsPlacement(*placement) {
+			return fmt.Errorf("unknown placement %q of %s, must be one of %s", *placement, key, strings.Join(imports.Placements, ", "))
+		}
+	}
+	switch s := v.GetString("sort"); s {
+	case "", imports.SortLexical:
+		opts.Sort = nil
+	case imports.SortNatural:
+		opts.Sort = imports.NaturalLess
+	default:
+		return fmt.Errorf("unknown sort %q, must be one of %s", s, strings.Join(imports.Sorts, ", "))
+	}
+	if !cmd.Flags().Changed("split-group") {
+		opts.SplitGroups = v.GetStringSlice("splitGroups")
+	}
+	opts.Aliases, opts.Deny, opts.Overrides = nil, nil, nil
+	if err := v.UnmarshalKey("aliases", &opts.Aliases); err != nil {
+		return fmt.Errorf("invalid aliases in config file: %v", err)
+	}
+	if err := v.UnmarshalKey("deny", &opts.Deny); err != nil {
+		return fmt.Errorf("invalid deny rules in config file: %v", err)
+	}
+	if err := v.UnmarshalKey("overrides", &opts.Overrides); err != nil {
+		return fmt.Errorf("invalid overrides in config file: %v", err)
+	}
+	if err := imports.CheckOptions(opts); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	return nil
+}
+
+// findGoModFile returns the path of the go.mod file of the module containing
+// path, or an empty string when there is none.
+func findGoModFile(path string) (string, error) {
 	if s, err := os.Stat(path); err != nil {
 		return "", err
 	} else if !s.IsDir() {
@@ -200,14 +386,47 @@ func findGoModule(path string) (string, error) {
 	if path == "." {
 		return "", nil
 	}
+	return fmt.Sprintf("%s/go.mod", path), nil
+}
+
+func findGoModule(path string) (string, error) {
+	modFilePath, err := findGoModFile(path)
+	if err != nil || modFilePath == "" {
+		return "", err
+	}
 
-	f, err := ioutil.ReadFile(fmt.Sprintf("%s/go.mod", path))
+	f, err := os.ReadFile(modFilePath)
 	if err != nil {
 		return "", fmt.Errorf("unable to open go.mod file for reading: %v", err)
 	}
-	module = modfile.ModulePath(f)
+	module := modfile.ModulePath(f)
 	if len(module) == 0 {
 		return "", fmt.Errorf("unable to automatically determine module path, please provide one using the --module flag")
 	}
 	return module, nil
 }
+
+// findRequirements returns the modules required by the go.mod file of the
+// module containing path.
+func findRequirements(path string) []gomodule.Version {
+	modFilePath, err := findGoModFile(path)
+	if err != nil || modFilePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(modFilePath)
+	if err != nil {
+		klog.Warningf("unable to open go.mod file for reading: %v", err)
+		return nil
+	}
+	f, err := modfile.Parse(modFilePath, data, nil)
+	if err != nil {
+		klog.Warningf("unable to parse go.mod file: %v", err)
+		return nil
+	}
+	var requirements []gomodule.Version
+	for _, r := range f.Require {
+		requirements = append(requirements, r.Mod)
+	}
+	return requirements
+}
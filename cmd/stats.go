@@ -0,0 +1,124 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/imports"
+)
+
+var (
+	statsOutput   string
+	statsPackages []string
+	statsFiles    bool
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats [path]",
+	Short: "Count imports per group, module and package.",
+	Long: `Stats walks the go files the same way as organizing imports does, and
+instead of changing them counts their imports per group, per module and per
+package.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			if len(path) > 0 {
+				klog.Errorf("path cannot be specified with a path argument")
+				os.Exit(1)
+			}
+			path = args[0]
+		} else if len(path) == 0 {
+			path = "."
+		}
+		if statsOutput != "table" && statsOutput != "json" {
+			klog.Errorf("unknown output %q, must be one of table, json", statsOutput)
+			os.Exit(1)
+		}
+
+		opts := loadOptions(cmd, path)
+		opts.Reporter = imports.NewReporter(os.Stderr, imports.OutputText)
+		opts.ForDir = newDirConfigs(cmd, opts).forDir
+		stats := imports.NewStats(statsPackages)
+		ctx := signalContext()
+		files := newQueue()
+		var wg sync.WaitGroup
+		for i := 0; i < cap(files); i++ {
+			wg.Add(1)
+			go imports.CollectStats(ctx, files, &wg, opts, stats)
+		}
+		queueFiles(ctx, cmd, path, files, &wg)
+		wg.Wait()
+		if ctx.Err() != nil {
+			klog.Warning("Interrupted before all the files were walked, leaving the statistics out")
+			os.Exit(1)
+		}
+		if opts.Reporter.Count() > 0 {
+			klog.Warning("Some files could not be counted, leaving the statistics out")
+			os.Exit(1)
+		}
+
+		if statsOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(stats); err != nil {
+				klog.Error(err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "FILES\t%d\n", stats.Files)
+		fmt.Fprintf(w, "IMPORTS\t%d\n", stats.Imports)
+		fmt.Fprintf(w, "\nGROUP\tIMPORTS\n")
+		for _, c := range imports.Sorted(stats.Groups) {
+			fmt.Fprintf(w, "%s\t%d\n", c.Name, c.Count)
+		}
+		fmt.Fprintf(w, "\nMODULE\tIMPORTS\n")
+		for _, c := range imports.Sorted(stats.Modules) {
+			fmt.Fprintf(w, "%s\t%d\n", c.Name, c.Count)
+		}
+		fmt.Fprintf(w, "\nPACKAGE\tFILES\n")
+		for _, c := range imports.Sorted(stats.PackageCounts()) {
+			fmt.Fprintf(w, "%s\t%d\n", c.Name, c.Count)
+			if statsFiles {
+				for _, file := range stats.Packages[c.Name] {
+					fmt.Fprintf(w, "  %s\t\n", file)
+				}
+			}
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVarP(&statsOutput, "output", "o", "table", "Output format, one of: table, json")
+	statsCmd.Flags().StringArrayVar(&statsPackages, "package", []string{}, "Only count imports of this package, or of the packages under it when followed by /... Example usage: --package io/ioutil --package github.com/openshift/library-go/...")
+	statsCmd.Flags().BoolVar(&statsFiles, "files", false, "List the files importing each package in the table output")
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+
+	"k8s.io/klog/v2"
+)
+
+// AliasRule requires the imports of some packages to use an alias.
+type AliasRule struct {
+	// Path is the import path the rule applies to.
+	Path string `mapstructure:"path"`
+	// Pattern is a regular expression matching the whole import paths the
+	// rule applies to, used when Path is empty.
+	Pattern string `mapstructure:"pattern"`
+	// Alias is the alias to use. When the rule has a Pattern, its named and
+	// numbered groups can be referenced as {name} or {1}, for example
+	// {group}{version}.
+	Alias string `mapstructure:"alias"`
+}
+
+// aliasRule is a compiled AliasRule.
+type aliasRule struct {
+	path     string
+	re       *regexp.Regexp
+	template string
+}
+
+var aliasTemplateRef = regexp.MustCompile(`\{(\w+)\}`)
+
+func newAliasRules(rules []AliasRule) ([]aliasRule, error) {
+	var compiled []aliasRule
+	for _, rule := range rules {
+		c := aliasRule{path: rule.Path, template: rule.Alias}
+		if len(rule.Path) == 0 {
+			re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of alias %q: %v", rule.Alias, err)
+			}
+			c.re = re
+			c.template = aliasTemplateRef.ReplaceAllString(rule.Alias, "$${$1}")
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// alias returns the alias required for importPath, if any.
+func (r aliasRule) alias(importPath string) (string, bool) {
+	if r.re == nil {
+		return r.template, importPath == r.path
+	}
+	m := r.re.FindStringSubmatchIndex(importPath)
+	if m == nil {
+		return "", false
+	}
+	return string(r.re.ExpandString(nil, r.template, importPath, m)), true
+}
+
+// checkAliases reports the imports of f that do not use the alias required by
+// the first matching rule, renaming them instead when fix is set.
+func (r *resolver) checkAliases(fs *token.FileSet, f *ast.File, path string, rules []aliasRule, fix bool, reporter *Reporter) {
+	for _, i := range f.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := r.importName(i)
+		if name == "_" || name == "." {
+			continue
+		}
+		for _, rule := range rules {
+			alias, ok := rule.alias(importPath)
+			if !ok {
+				continue
+			}
+			line := fs.Position(i.Pos()).Line
+			switch {
+			case !token.IsIdentifier(alias):
+				reporter.Report(path, line, fmt.Sprintf("alias %q required for import %s is not a valid identifier", alias, i.Path.Value))
+			case name == alias:
+			case !fix:
+				reporter.Report(path, line, fmt.Sprintf("import %s should be aliased %s", i.Path.Value, alias))
+			default:
+				if err := r.renameImport(f, i, alias); err != nil {
+					reporter.Report(path, line, err.Error())
+				} else {
+					klog.V(2).Infof("%s: aliased import %s as %s", path, i.Path.Value, alias)
+				}
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package imports
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestCheckAliases(t *testing.T) {
+	rules, err := newAliasRules([]AliasRule{
+		{Path: "k8s.io/apimachinery/pkg/apis/meta/v1", Alias: "metav1"},
+		{Pattern: `(?:k8s.io|github.com/openshift)/api/(?P<group>[^/]+)/(?P<version>v[^/]+)`, Alias: "{group}{version}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		src    string
+		fix    bool
+		want   string
+		report string
+	}{
+		{
+			name: "report",
+			src: `package main
+
+import meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+var _ = meta.ObjectMeta{}
+`,
+			want: `package main
+
+import meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+var _ = meta.ObjectMeta{}
+`,
+			report: "example.go:3: import \"k8s.io/apimachinery/pkg/apis/meta/v1\" should be aliased metav1\n",
+		},
+		{
+			name: "fix",
+			src: `package main
+
+import (
+	"k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func f(meta int) v1.Pod {
+	_ = configv1.ClusterVersion{}
+	return v1.Pod{ObjectMeta: meta.ObjectMeta{}}
+}
+`,
+			fix: true,
+			want: `package main
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func f(meta int) corev1.Pod {
+	_ = configv1.ClusterVersion{}
+	return corev1.Pod{ObjectMeta: meta.ObjectMeta{}}
+}
+`,
+		},
+		{
+			name: "alias already used",
+			src: `package main
+
+import "k8s.io/api/core/v1"
+
+var corev1 = v1.Pod{}
+`,
+			fix: true,
+			want: `package main
+
+import "k8s.io/api/core/v1"
+
+var corev1 = v1.Pod{}
+`,
+			report: "example.go:3: cannot rename import \"k8s.io/api/core/v1\" to corev1, corev1 is already used in the file\n",
+		},
+	}
+
+	for _, test := range tests {
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, "example.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("test: %s, failed to parse: %v", test.name, err)
+		}
+		var report bytes.Buffer
+		newResolver(&Options{}).checkAliases(fs, f, "example.go", rules, test.fix, NewReporter(&report, OutputText))
+		var out bytes.Buffer
+		if err := format.Node(&out, fs, f); err != nil {
+			t.Fatalf("test: %s, failed to print: %v", test.name, err)
+		}
+		if out.String() != test.want {
+			t.Errorf("test: %s, wanted: %s, got %s", test.name, test.want, out.String())
+		}
+		if report.String() != test.report {
+			t.Errorf("test: %s, wanted report: %q, got %q", test.name, test.report, report.String())
+		}
+	}
+}
+
+func TestNewAliasRulesInvalidPattern(t *testing.T) {
+	_, err := newAliasRules([]AliasRule{{Pattern: "k8s.io/api/(", Alias: "api"}})
+	if err == nil || !strings.Contains(err.Error(), `alias "api"`) {
+		t.Errorf("wanted an error naming the alias, got %v", err)
+	}
+	if err := CheckOptions(&Options{Module: "github.com/example/module", Aliases: []AliasRule{{Pattern: "(", Alias: "api"}}}); err == nil {
+		t.Errorf("wanted CheckOptions to report the invalid pattern")
+	}
+}
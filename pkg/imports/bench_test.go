@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/util"
+)
+
+// writeTree writes a module of packages packages of files go files each, with
+// imports of every group in no particular order, under dir.
+func writeTree(b *testing.B, dir string, packages, files int) {
+	b.Helper()
+	for p := 0; p < packages; p++ {
+		pkg := filepath.Join(dir, "pkg", fmt.Sprintf("p%d", p))
+		if err := os.MkdirAll(pkg, 0755); err != nil {
+			b.Fatal(err)
+		}
+		for n := 0; n < files; n++ {
+			src := fmt.Sprintf(`package p%d
+
+import (
+	"github.com/spf13/cobra"
+	"os"
+	corev1 "k8s.io/api/core/v1"
+	"github.com/example/module/pkg/p%d"
+	"fmt"
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"strings"
+)
+
+func F%d(cmd *cobra.Command) error {
+	pod := corev1.Pod{}
+	config := configv1.ClusterVersion{}
+	names := sets.NewString(strings.Fields(os.Getenv("NAMES"))...)
+	fmt.Println(pod.Name, config.Name, names.Len(), p%d.F0)
+	return nil
+}
+`, p, (p+1)%packages, n, (p+1)%packages)
+			if err := os.WriteFile(filepath.Join(pkg, fmt.Sprintf("f%d.go", n)), []byte(src), 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkFormat checks the imports of a tree of 1000 files, walking it as
+// the command does, with an increasing number of workers.
+func BenchmarkFormat(b *testing.B) {
+	dir := b.TempDir()
+	writeTree(b, dir, 50, 20)
+
+	jobs := []int{1, 2, 4}
+	if procs := runtime.GOMAXPROCS(0); procs > 4 {
+		jobs = append(jobs, procs)
+	}
+	for _, workers := range jobs {
+		b.Run(fmt.Sprintf("jobs=%d", workers), func(b *testing.B) {
+			// the github output reports the files instead of writing them,
+			// so each iteration does the same work
+			opts := &Options{
+				Module:    "github.com/example/module",
+				ModuleDir: dir,
+				Output:    OutputGitHub,
+				Reporter:  NewReporter(io.Discard, OutputGitHub),
+			}
+			count := 0
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				files := make(chan string, workers)
+				var wg sync.WaitGroup
+				for w := 0; w < workers; w++ {
+					wg.Add(1)
+					go FormatWithOptions(files, &wg, opts)
+				}
+				walker, err := util.NewWalker(dir, nil, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				err = walker.Walk(context.Background(), func(path string) {
+					count++
+					files <- path
+				})
+				close(files)
+				wg.Wait()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(count)/time.Since(start).Seconds(), "files/s")
+		})
+	}
+}
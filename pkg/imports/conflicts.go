@@ -0,0 +1,248 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"k8s.io/klog/v2"
+)
+
+// DefaultAliasTemplate is the template of the aliases given to imports whose
+// package names conflict, for example corev1 for k8s.io/api/core/v1.
+const DefaultAliasTemplate = "{parent}{name}"
+
+// conflictAlias returns the alias of the import of package name at importPath
+// built from template, where {name} is the package name, and {parent} and
+// {grandparent} are the elements of the import path before the package
+// directory. When level is positive, that many more elements are prepended
+// to tell apart imports whose aliases are still the same.
+func conflictAlias(importPath, name, template string, level int) (string, bool) {
+	elems := strings.Split(importPath, "/")
+	if len(elems) > 1 && isMajorVersion(elems[len(elems)-1]) {
+		elems = elems[:len(elems)-1]
+	}
+	ancestors := elems[:len(elems)-1]
+	ancestor := func(n int) string {
+		if n >= len(ancestors) {
+			return ""
+		}
+		return identifierPart(ancestors[len(ancestors)-1-n])
+	}
+	if level >= len(ancestors) {
+		return "", false
+	}
+
+	alias := strings.NewReplacer("{name}", name, "{parent}", ancestor(0), "{grandparent}", ancestor(1)).Replace(template)
+	for n := 1; n <= level; n++ {
+		alias = ancestor(n) + alias
+	}
+	return alias, true
+}
+
+// identifierPart returns s without the characters not valid in an identifier,
+// lower cased.
+func identifierPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// aliasConflicts gives the imports of f whose effective names, their alias or
+// else their package name, are the same an alias built from template, and
+// rewrites their uses, told apart by the names their packages export. An
+// import whose path has no element left to build a distinct alias from keeps
+// its name.
+func (r *resolver) aliasConflicts(fs *token.FileSet, f *ast.File, path, template string, reporter *Reporter) {
+	byName := map[string][]*ast.ImportSpec{}
+	var names []string
+	for _, i := range f.Imports {
+		name := r.importName(i)
+		if name == "" || name == "_" || name == "." {
+			continue
+		}
+		if len(byName[name]) == 0 {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], i)
+	}
+	sort.Strings(names)
+
+	targets := map[*ast.ImportSpec]string{}
+	uses := map[*ast.ImportSpec][]*ast.Ident{}
+	for _, name := range names {
+		conflicting := byName[name]
+		if len(conflicting) < 2 {
+			continue
+		}
+		owners, unknown := r.attributeUses(f, name, conflicting)
+		if unknown != nil {
+			reporter.Report(path, fs.Position(unknown.Pos()).Line, fmt.Sprintf("cannot tell which of the imports named %s declares %s", name, unknown.Sel.Name))
+			continue
+		}
+		aliases, err := r.conflictAliases(conflicting, name, template)
+		if err != nil {
+			reporter.Report(path, fs.Position(conflicting[1].Pos()).Line, err.Error())
+			continue
+		}
+		for idx, i := range conflicting {
+			if aliases[idx] != "" {
+				targets[i] = aliases[idx]
+				uses[i] = owners[i]
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	// Find the uses of the current names before renaming anything, and make
+	// sure none of the new names is already used by something else.
+	renamed := map[*ast.Ident]bool{}
+	wanted := map[string]*ast.ImportSpec{}
+	for i, alias := range targets {
+		for _, id := range uses[i] {
+			renamed[id] = true
+		}
+		if i.Name != nil {
+			renamed[i.Name] = true
+		}
+		wanted[alias] = i
+	}
+	for _, other := range f.Imports {
+		if i, ok := wanted[r.importName(other)]; ok && targets[other] == "" {
+			reporter.Report(path, fs.Position(i.Pos()).Line, fmt.Sprintf("cannot alias import %s as %s, %s is already imported as %s", i.Path.Value, targets[i], other.Path.Value, targets[i]))
+			return
+		}
+	}
+	var conflict *ast.Ident
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && wanted[id.Name] != nil && !renamed[id] {
+			conflict = id
+		}
+		return conflict == nil
+	})
+	if conflict != nil {
+		i := wanted[conflict.Name]
+		reporter.Report(path, fs.Position(i.Pos()).Line, fmt.Sprintf("cannot alias import %s as %s, %s is already used in the file", i.Path.Value, conflict.Name, conflict.Name))
+		return
+	}
+
+	for i, alias := range targets {
+		for _, id := range uses[i] {
+			id.Name = alias
+		}
+		if i.Name == nil || i.Name.Name != alias {
+			klog.V(2).Infof("%s: aliased conflicting import %s as %s", path, i.Path.Value, alias)
+		}
+		i.Name = &ast.Ident{NamePos: i.Path.Pos(), Name: alias}
+	}
+}
+
+// attributeUses returns the uses of name in f by each of the imports
+// conflicting, all named name, the package of each use being the only one of
+// them exporting the selected name. Otherwise, the selector of the use is
+// returned.
+func (r *resolver) attributeUses(f *ast.File, name string, conflicting []*ast.ImportSpec) (map[*ast.ImportSpec][]*ast.Ident, *ast.SelectorExpr) {
+	exported := make([]map[string]bool, len(conflicting))
+	for idx, i := range conflicting {
+		importPath, _ := strconv.Unquote(i.Path.Value)
+		if dir, ok := r.dir(importPath); ok {
+			exported[idx] = exportedNames(dir)
+		}
+	}
+	owners := map[*ast.ImportSpec][]*ast.Ident{}
+	for _, sel := range importSelectors(f, name) {
+		var owner *ast.ImportSpec
+		for idx, i := range conflicting {
+			if !exported[idx][sel.Sel.Name] {
+				continue
+			}
+			if owner != nil {
+				return nil, sel
+			}
+			owner = i
+		}
+		if owner == nil {
+			return nil, sel
+		}
+		owners[owner] = append(owners[owner], sel.X.(*ast.Ident))
+	}
+	return owners, nil
+}
+
+// conflictAliases returns distinct aliases for the imports named name,
+// prepending more elements of their import paths until they differ. The
+// first import running out of elements keeps its name, its alias being
+// empty.
+func (r *resolver) conflictAliases(conflicting []*ast.ImportSpec, name, template string) ([]string, error) {
+	aliases := make([]string, len(conflicting))
+	levels := make([]int, len(conflicting))
+	kept := -1
+next:
+	for {
+		seen := map[string][]int{}
+		if kept >= 0 {
+			aliases[kept] = ""
+			seen[name] = []int{kept}
+		}
+		for idx, i := range conflicting {
+			if idx == kept {
+				continue
+			}
+			importPath, _ := strconv.Unquote(i.Path.Value)
+			// the package name, whatever the current alias
+			pkg := r.importName(&ast.ImportSpec{Path: i.Path})
+			alias, ok := conflictAlias(importPath, pkg, template, levels[idx])
+			if !ok {
+				if kept >= 0 {
+					return nil, fmt.Errorf("cannot find distinct aliases for the imports named %s", name)
+				}
+				kept = idx
+				continue next
+			}
+			if !token.IsIdentifier(alias) || alias == "_" {
+				return nil, fmt.Errorf("alias %q for import %s is not a valid identifier", alias, i.Path.Value)
+			}
+			aliases[idx] = alias
+			seen[alias] = append(seen[alias], idx)
+		}
+		done := true
+		for _, same := range seen {
+			if len(same) < 2 {
+				continue
+			}
+			done = false
+			for _, idx := range same {
+				if idx != kept {
+					levels[idx]++
+				}
+			}
+		}
+		if done {
+			return aliases, nil
+		}
+	}
+}
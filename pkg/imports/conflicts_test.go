@@ -0,0 +1,222 @@
+package imports
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAliasConflicts(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"api/core/v1/types.go":   "package v1\n\ntype Pod struct{}\n",
+		"api/config/v1/types.go": "package v1\n\ntype ClusterVersion struct{}\n",
+		"apis/core/v1/types.go":  "package v1\n\ntype Service struct{}\n",
+		"hcl/strconv/quote.go":   "package strconv\n\nfunc UnquoteHCL(s string) string { return s }\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		template string
+		want     string
+		report   string
+	}{
+		{
+			name: "conflicting versions",
+			src: `package main
+
+import (
+	"github.com/example/module/api/core/v1"
+	"github.com/example/module/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = []interface{}{v1.Pod{}, v1.ClusterVersion{}, metav1.ObjectMeta{}}
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	configv1 "github.com/example/module/api/config/v1"
+	corev1 "github.com/example/module/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = []interface{}{corev1.Pod{}, configv1.ClusterVersion{}, metav1.ObjectMeta{}}
+`,
+		},
+		{
+			name: "same parent",
+			src: `package main
+
+import (
+	v1 "github.com/example/module/apis/core/v1"
+	"github.com/example/module/api/core/v1"
+)
+
+var _ = []interface{}{v1.Pod{}, v1.Service{}}
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	apicorev1 "github.com/example/module/api/core/v1"
+	apiscorev1 "github.com/example/module/apis/core/v1"
+)
+
+var _ = []interface{}{apicorev1.Pod{}, apiscorev1.Service{}}
+`,
+		},
+		{
+			name: "custom template",
+			src: `package main
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+var _ = []interface{}{rand.Reader, rand.Intn}
+`,
+			template: "{parent}_{name}",
+			want: `package main
+
+import (
+	crypto_rand "crypto/rand"
+	math_rand "math/rand"
+)
+
+var _ = []interface{}{crypto_rand.Reader, math_rand.Intn}
+`,
+		},
+		{
+			name: "distinct aliases",
+			src: `package main
+
+import (
+	"crypto/rand"
+	mrand "math/rand"
+	"strconv"
+	hclstrconv "github.com/example/module/hcl/strconv"
+)
+
+var _ = []interface{}{rand.Reader, mrand.Intn, strconv.Itoa, hclstrconv.UnquoteHCL}
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	"crypto/rand"
+	hclstrconv "github.com/example/module/hcl/strconv"
+	mrand "math/rand"
+	"strconv"
+)
+
+var _ = []interface{}{rand.Reader, mrand.Intn, strconv.Itoa, hclstrconv.UnquoteHCL}
+`,
+		},
+		{
+			name: "no element left",
+			src: `package main
+
+import (
+	"strconv"
+	"github.com/example/module/hcl/strconv"
+)
+
+var _ = []interface{}{strconv.Itoa, strconv.UnquoteHCL}
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	hclstrconv "github.com/example/module/hcl/strconv"
+	"strconv"
+)
+
+var _ = []interface{}{strconv.Itoa, hclstrconv.UnquoteHCL}
+`,
+		},
+		{
+			name: "ambiguous use",
+			src: `package main
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+var _ = rand.Int
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+var _ = rand.Int
+`,
+			report: "example.go:8: cannot tell which of the imports named rand declares Int\n",
+		},
+		{
+			name: "alias already used",
+			src: `package main
+
+import (
+	"github.com/example/module/api/core/v1"
+	"github.com/example/module/api/config/v1"
+)
+
+var corev1 = v1.Pod{}
+var _ = v1.ClusterVersion{}
+`,
+			template: DefaultAliasTemplate,
+			want: `package main
+
+import (
+	"github.com/example/module/api/config/v1"
+	"github.com/example/module/api/core/v1"
+)
+
+var corev1 = v1.Pod{}
+var _ = v1.ClusterVersion{}
+`,
+			report: "example.go:4: cannot alias import \"github.com/example/module/api/core/v1\" as corev1, corev1 is already used in the file\n",
+		},
+	}
+
+	for _, test := range tests {
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, "example.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("test: %s, failed to parse: %v", test.name, err)
+		}
+		var report bytes.Buffer
+		newResolver(&Options{Module: "github.com/example/module", ModuleDir: dir}).aliasConflicts(fs, f, "example.go", test.template, NewReporter(&report, OutputText))
+		var out bytes.Buffer
+		if err := format.Node(&out, fs, f); err != nil {
+			t.Fatalf("test: %s, failed to print: %v", test.name, err)
+		}
+		if out.String() != test.want {
+			t.Errorf("test: %s, wanted: %s, got %s", test.name, test.want, out.String())
+		}
+		if report.String() != test.report {
+			t.Errorf("test: %s, wanted report: %q, got %q", test.name, test.report, report.String())
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+)
+
+// DenyRule forbids importing some packages.
+type DenyRule struct {
+	// Path is the import path the rule applies to, or an import path followed
+	// by /... to also apply to the packages under it.
+	Path string `mapstructure:"path"`
+	// Pattern is a regular expression matching the whole import paths the
+	// rule applies to, used when Path is empty.
+	Pattern string `mapstructure:"pattern"`
+	// Reason explains why the packages are denied.
+	Reason string `mapstructure:"reason"`
+	// Replacement is what to use instead, if anything.
+	Replacement string `mapstructure:"replacement"`
+}
+
+// denyRule is a compiled DenyRule.
+type denyRule struct {
+	DenyRule
+	re *regexp.Regexp
+}
+
+func newDenyRules(rules []DenyRule) ([]denyRule, error) {
+	var compiled []denyRule
+	for _, rule := range rules {
+		c := denyRule{DenyRule: rule}
+		if len(rule.Path) == 0 {
+			re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of denied imports %q: %v", rule.Pattern, err)
+			}
+			c.re = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches returns whether the rule applies to importPath.
+func (r denyRule) matches(importPath string) bool {
+	if r.re != nil {
+		return r.re.MatchString(importPath)
+	}
+	return matchPackage(importPath, r.Path)
+}
+
+// message describes why importing value is denied.
+func (r denyRule) message(value string) string {
+	message := fmt.Sprintf("import %s is denied", value)
+	if r.Reason != "" {
+		message += ": " + r.Reason
+	}
+	if r.Replacement != "" {
+		message += fmt.Sprintf(", use %s instead", r.Replacement)
+	}
+	return message
+}
+
+// checkDenied reports the imports of f matching one of rules.
+func checkDenied(fs *token.FileSet, f *ast.File, path string, rules []denyRule, reporter *Reporter) {
+	for _, i := range f.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.matches(importPath) {
+				reporter.Report(path, fs.Position(i.Pos()).Line, rule.message(i.Path.Value))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package imports
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestCheckDenied(t *testing.T) {
+	rules, err := newDenyRules([]DenyRule{
+		{Path: "io/ioutil", Reason: "deprecated since Go 1.16", Replacement: "os or io"},
+		{Path: "github.com/golang/protobuf/...", Replacement: "google.golang.org/protobuf"},
+		{Pattern: `github\.com/pkg/errors`, Reason: "use the standard library"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := `package main
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobufs"
+	"github.com/pkg/errors"
+	"github.com/pkg/errors/sub"
+)
+`
+	want := `example.go:5: import "io/ioutil" is denied: deprecated since Go 1.16, use os or io instead
+example.go:7: import "github.com/golang/protobuf/proto" is denied, use google.golang.org/protobuf instead
+example.go:9: import "github.com/pkg/errors" is denied: use the standard library
+`
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "example.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var report bytes.Buffer
+	checkDenied(fs, f, "example.go", rules, NewReporter(&report, OutputText))
+	if report.String() != want {
+		t.Errorf("wanted: %q, got %q", want, report.String())
+	}
+}
+
+func TestNewDenyRulesInvalidPattern(t *testing.T) {
+	_, err := newDenyRules([]DenyRule{{Pattern: `github\.com/pkg/(errors`, Reason: "use the standard library"}})
+	if err == nil || !strings.Contains(err.Error(), `github\\.com/pkg/(errors`) {
+		t.Errorf("wanted an error naming the pattern, got %v", err)
+	}
+	if err := CheckOptions(&Options{Module: "github.com/example/module", Deny: []DenyRule{{Pattern: "("}}}); err == nil {
+		t.Errorf("wanted CheckOptions to report the invalid pattern")
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const (
+	// IgnoreDirective, in a comment before the imports of a file, leaves the
+	// file as it is.
+	IgnoreDirective = "//openshift-goimports:ignore"
+	// FreezeDirective, in a comment of an import block, leaves the imports
+	// following it up to the next blank line as they are.
+	FreezeDirective = "//openshift-goimports:freeze"
+)
+
+// hasDirective returns whether one of the comments of group is directive,
+// optionally followed by a space and an explanation.
+func hasDirective(group *ast.CommentGroup, directive string) bool {
+	for _, c := range group.List {
+		if c.Text == directive || strings.HasPrefix(c.Text, directive+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored returns whether f has the ignore directive in a comment before
+// its first import declaration, or before its first declaration when it has
+// no imports.
+func isIgnored(f *ast.File) bool {
+	end := token.NoPos
+	if len(f.Decls) > 0 {
+		end = f.Decls[0].Pos()
+	}
+	for _, group := range f.Comments {
+		if end.IsValid() && group.Pos() >= end {
+			break
+		}
+		if hasDirective(group, IgnoreDirective) {
+			return true
+		}
+	}
+	return false
+}
+
+// frozenSpec is an import of a frozen group, with the offsets of its text in
+// the original file.
+type frozenSpec struct {
+	spec       *ast.ImportSpec
+	start, end int
+}
+
+// frozenGroup is a group of imports following the freeze directive, kept as
+// written at the top of its import block when it comes before the other
+// imports, and at the bottom otherwise.
+type frozenGroup struct {
+	// decl is the import declaration of the group.
+	decl *ast.GenDecl
+	// start and end are the offsets of the lines of the group, from the
+	// directive to the end of the line of its last import.
+	start, end int
+	specs      []frozenSpec
+	leading    bool
+}
+
+// frozenGroups returns the groups of imports of f following a freeze
+// directive, along with the set of their imports.
+func frozenGroups(fs *token.FileSet, f *ast.File) ([]frozenGroup, map[*ast.ImportSpec]bool) {
+	var groups []frozenGroup
+	frozen := map[*ast.ImportSpec]bool{}
+	file := fs.File(f.Pos())
+	lineStart := func(line int) int {
+		if line > file.LineCount() {
+			return file.Size()
+		}
+		return file.Offset(file.LineStart(line))
+	}
+
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		for _, group := range f.Comments {
+			if group.Pos() < gen.Lparen || group.End() > gen.Rparen || !hasDirective(group, FreezeDirective) {
+				continue
+			}
+			frozenGroup := frozenGroup{decl: gen, start: lineStart(file.Line(group.Pos())), leading: true}
+			last := file.Line(group.End())
+			for _, spec := range gen.Specs {
+				i := spec.(*ast.ImportSpec)
+				if i.Pos() < group.End() {
+					if !frozen[i] {
+						frozenGroup.leading = false
+					}
+					continue
+				}
+				if hasBlankLine(f, file, last, file.Line(i.Pos())) {
+					break
+				}
+				frozen[i] = true
+				frozenGroup.specs = append(frozenGroup.specs, frozenSpec{spec: i, start: file.Offset(i.Pos()), end: file.Offset(i.End())})
+				last = file.Line(i.End())
+			}
+			if len(frozenGroup.specs) == 0 {
+				continue
+			}
+			frozenGroup.end = lineStart(last + 1)
+			groups = append(groups, frozenGroup)
+		}
+	}
+	return groups, frozen
+}
+
+// hasBlankLine returns whether a line strictly between the lines from and to
+// of file is blank, that is neither holds code nor a comment of f.
+func hasBlankLine(f *ast.File, file *token.File, from, to int) bool {
+	for line := from + 1; line < to; line++ {
+		commented := false
+		for _, group := range f.Comments {
+			if file.Line(group.Pos()) <= line && line <= file.Line(group.End()) {
+				commented = true
+				break
+			}
+		}
+		if !commented {
+			return true
+		}
+	}
+	return false
+}
+
+// removeComments drops the comments of the frozen groups from f, since their
+// text is put back as written.
+func removeComments(fs *token.FileSet, f *ast.File, groups []frozenGroup) {
+	file := fs.File(f.Pos())
+	comments := f.Comments[:0]
+	for _, c := range f.Comments {
+		offset := file.Offset(c.Pos())
+		kept := true
+		for _, group := range groups {
+			if group.start <= offset && offset < group.end {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			comments = append(comments, c)
+		}
+	}
+	f.Comments = comments
+}
+
+// text returns the lines of the group as written in contents, with the
+// imports changed since then, such as renamed ones, updated and the removed
+// ones dropped.
+func (g frozenGroup) text(f *ast.File, contents []byte) []byte {
+	imported := map[*ast.ImportSpec]bool{}
+	for _, i := range f.Imports {
+		imported[i] = true
+	}
+	var out bytes.Buffer
+	offset := g.start
+	for _, s := range g.specs {
+		out.Write(contents[offset:s.start])
+		if imported[s.spec] {
+			if s.spec.Name != nil {
+				out.WriteString(s.spec.Name.Name + " ")
+			}
+			out.WriteString(s.spec.Path.Value)
+		}
+		offset = s.end
+	}
+	out.Write(contents[offset:g.end])
+
+	lines := bytes.SplitAfter(out.Bytes(), []byte("\n"))
+	out.Reset()
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) > 0 {
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
+
+// insertFrozen puts the frozen groups, as written in contents, back in their
+// import blocks of the printed file out, separated from the other imports by
+// blank lines.
+func insertFrozen(out []byte, f *ast.File, contents []byte, groups []frozenGroup) []byte {
+	// the import declarations of f are printed in order, so the n-th import
+	// line of out is the n-th import declaration of f
+	decls := map[*ast.GenDecl]int{}
+	last := -1
+	for _, d := range f.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decls[gen] = len(decls)
+		}
+	}
+	for _, g := range groups {
+		if n, ok := decls[g.decl]; ok && n > last {
+			last = n
+		}
+	}
+
+	lines := bytes.SplitAfter(out, []byte("\n"))
+	var result bytes.Buffer
+	decl := -1
+	for n := 0; n < len(lines); n++ {
+		line := lines[n]
+		trimmed := bytes.TrimSpace(line)
+		if decl >= last || !bytes.HasPrefix(line, []byte("import")) {
+			result.Write(line)
+			continue
+		}
+		decl++
+		if !bytes.HasSuffix(trimmed, []byte("(")) && !bytes.HasSuffix(trimmed, []byte("()")) {
+			result.Write(line)
+			continue
+		}
+
+		var parts [][]byte
+		for _, g := range groups {
+			if n, ok := decls[g.decl]; ok && n == decl && g.leading {
+				parts = append(parts, g.text(f, contents))
+			}
+		}
+		result.WriteString("import (\n")
+		if bytes.HasSuffix(trimmed, []byte("(")) {
+			var block []byte
+			for n++; n < len(lines) && !bytes.Equal(bytes.TrimSpace(lines[n]), []byte(")")); n++ {
+				block = append(block, lines[n]...)
+			}
+			if len(block) > 0 {
+				parts = append(parts, block)
+			}
+		}
+		for _, g := range groups {
+			if n, ok := decls[g.decl]; ok && n == decl && !g.leading {
+				parts = append(parts, g.text(f, contents))
+			}
+		}
+		result.Write(bytes.Join(parts, []byte("\n")))
+		result.WriteString(")\n")
+	}
+	return result.Bytes()
+}
@@ -0,0 +1,168 @@
+package imports
+
+import (
+	"testing"
+)
+
+func TestIgnoreDirective(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "before the package clause",
+			src: `// Package main does things.
+//
+//openshift-goimports:ignore
+package main
+
+import (
+	"os"
+	"fmt"
+)
+`,
+		},
+		{
+			name: "before the imports",
+			src: `package main
+
+//openshift-goimports:ignore the order matters
+import (
+	"os"
+	"fmt"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatFile(t, tt.src, &Options{}); got != tt.src {
+			t.Errorf("%s: wanted the file to be left as is, got:\n%s", tt.name, got)
+		}
+	}
+}
+
+func TestIgnoreDirectiveAfterImports(t *testing.T) {
+	src := `package main
+
+import (
+	"os"
+	"fmt"
+)
+
+//openshift-goimports:ignore
+var _ = fmt.Sprint(os.Args)
+`
+	if got := formatFile(t, src, &Options{}); got == src {
+		t.Errorf("wanted the directive to only apply before the imports")
+	}
+}
+
+func TestFreezeDirective(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "trailing group",
+			src: `package main
+
+import (
+	"os"
+	"fmt"
+
+	//openshift-goimports:freeze registration order
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	_ "github.com/example/plugin" // registers the plugin
+
+	"github.com/openshift/api"
+	"github.com/spf13/cobra"
+)
+`,
+			want: `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/api"
+
+	//openshift-goimports:freeze registration order
+	_ "github.com/example/plugin" // registers the plugin
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+`,
+		},
+		{
+			name: "leading group",
+			src: `package main
+
+import (
+	//openshift-goimports:freeze
+	_ "k8s.io/plugin"
+	_ "github.com/example/plugin"
+
+	"os"
+	"github.com/spf13/cobra"
+	"fmt"
+)
+`,
+			want: `package main
+
+import (
+	//openshift-goimports:freeze
+	_ "github.com/example/plugin"
+	_ "k8s.io/plugin"
+
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+`,
+		},
+		{
+			name: "only frozen imports",
+			src: `package main
+
+import (
+	//openshift-goimports:freeze
+	"k8s.io/api"
+	"fmt"
+)
+`,
+			want: `package main
+
+import (
+	//openshift-goimports:freeze
+	"fmt"
+	"k8s.io/api"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatFile(t, tt.src, &Options{}); got != tt.want {
+			t.Errorf("%s: wanted:\n%s\ngot:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFreezeDirectiveIsStable(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+	"os"
+
+	//openshift-goimports:freeze
+	_ "github.com/example/plugin"
+	_ "k8s.io/plugin"
+)
+`
+	if got := formatFile(t, src, &Options{}); got != src {
+		t.Errorf("wanted the file to be left as is, got:\n%s", got)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"k8s.io/klog/v2"
+)
+
+// duplicateImport is an import of a path already imported under another
+// name, both names being used by the file.
+type duplicateImport struct {
+	spec, first *ast.ImportSpec
+}
+
+func (d duplicateImport) message(fs *token.FileSet, r *resolver) string {
+	return fmt.Sprintf("import %s as %s repeats the import of line %d as %s, and both names are used", d.spec.Path.Value, r.importName(d.spec), fs.Position(d.first.Pos()).Line, r.importName(d.first))
+}
+
+// dedupeImports removes the imports of f repeating an earlier import of the
+// same path, either under the same name or under a name the file does not
+// use. It returns the repeated imports whose names are all used, which
+// cannot be removed.
+func (r *resolver) dedupeImports(f *ast.File, path string) []duplicateImport {
+	var values []string
+	byValue := map[string][]*ast.ImportSpec{}
+	for _, i := range f.Imports {
+		if _, ok := byValue[i.Path.Value]; !ok {
+			values = append(values, i.Path.Value)
+		}
+		byValue[i.Path.Value] = append(byValue[i.Path.Value], i)
+	}
+
+	var duplicates []duplicateImport
+	for _, value := range values {
+		specs := byValue[value]
+		if len(specs) < 2 {
+			continue
+		}
+		var named, used []*ast.ImportSpec
+		names := map[string]bool{}
+		for _, i := range specs {
+			name := r.importName(i)
+			if names[name] {
+				removeImport(f, i)
+				klog.V(2).Infof("%s: removed repeated import %s as %s", path, value, name)
+				continue
+			}
+			names[name] = true
+			named = append(named, i)
+			if name == "." || (name != "_" && len(importSelectors(f, name)) > 0) {
+				used = append(used, i)
+			}
+		}
+		if len(used) == 0 {
+			used = named[:1]
+		}
+		for _, i := range named {
+			if !containsSpec(used, i) {
+				removeImport(f, i)
+				klog.V(2).Infof("%s: removed repeated import %s as %s, which is not used", path, value, r.importName(i))
+			}
+		}
+		for _, i := range used[1:] {
+			duplicates = append(duplicates, duplicateImport{spec: i, first: used[0]})
+		}
+	}
+	return duplicates
+}
+
+func containsSpec(specs []*ast.ImportSpec, i *ast.ImportSpec) bool {
+	for _, s := range specs {
+		if s == i {
+			return true
+		}
+	}
+	return false
+}
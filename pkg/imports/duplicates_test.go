@@ -0,0 +1,163 @@
+package imports
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDedupeImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "same import twice",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+	"fmt"
+)
+
+var _ = fmt.Sprint(os.Args)
+`,
+			want: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+var _ = fmt.Sprint(os.Args)
+`,
+		},
+		{
+			name: "redundant alias",
+			src: `package main
+
+import (
+	"fmt"
+	fmt "fmt"
+)
+
+var _ = fmt.Sprint()
+`,
+			want: `package main
+
+import (
+	"fmt"
+)
+
+var _ = fmt.Sprint()
+`,
+		},
+		{
+			name: "unused alias",
+			src: `package main
+
+import (
+	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = corev1.Pod{}
+`,
+			want: `package main
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = corev1.Pod{}
+`,
+		},
+		{
+			name: "blank import of an imported package",
+			src: `package main
+
+import (
+	_ "embed"
+	"embed"
+)
+
+var _ embed.FS
+`,
+			want: `package main
+
+import (
+	"embed"
+)
+
+var _ embed.FS
+`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := formatFile(t, test.src, &Options{}); got != test.want {
+			t.Errorf("test: %s, wanted:\n%s\ngot:\n%s", test.name, test.want, got)
+		}
+	}
+}
+
+func TestDedupeImportsConflict(t *testing.T) {
+	src := `package main
+
+import (
+	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _, _ = v1.Pod{}, corev1.Pod{}
+`
+	var out bytes.Buffer
+	opts := &Options{Reporter: NewReporter(&out, OutputText)}
+	if got := formatFile(t, src, opts); got != src {
+		t.Errorf("wanted the file to be left as is, got:\n%s", got)
+	}
+	if want := `import "k8s.io/api/core/v1" as corev1 repeats the import of line 4 as v1, and both names are used`; !bytes.HasSuffix(out.Bytes(), []byte(":5: "+want+"\n")) {
+		t.Errorf("wanted %q to be reported on line 5, got %q", want, out.String())
+	}
+}
+
+func TestFormatMultipleDeclarations(t *testing.T) {
+	src := `package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import "os"
+
+import (
+	"github.com/spf13/cobra"
+	"fmt"
+	"os"
+)
+
+var _, _, _ = fmt.Sprint(os.Args), cobra.Command{}, C.free
+`
+	want := `package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import "os"
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var _, _, _ = fmt.Sprint(os.Args), cobra.Command{}, C.free
+`
+	if got := formatFile(t, src, &Options{}); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
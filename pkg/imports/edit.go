@@ -0,0 +1,91 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// findImport returns the first import of importPath in f, if any.
+func findImport(f *ast.File, importPath string) *ast.ImportSpec {
+	value := strconv.Quote(importPath)
+	for _, i := range f.Imports {
+		if i.Path.Value == value {
+			return i
+		}
+	}
+	return nil
+}
+
+// addImport adds an import of importPath named name, or unnamed when name is
+// empty, to the first import declaration of f, creating one if needed.
+func addImport(f *ast.File, name, importPath string) *ast.ImportSpec {
+	i := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(importPath)}}
+	if name != "" {
+		i.Name = ast.NewIdent(name)
+	}
+
+	var gen *ast.GenDecl
+	for _, decl := range f.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			gen = d
+			break
+		}
+	}
+	if gen == nil {
+		gen = &ast.GenDecl{Tok: token.IMPORT, TokPos: f.Name.End()}
+		f.Decls = append([]ast.Decl{gen}, f.Decls...)
+	}
+	if !gen.Lparen.IsValid() {
+		gen.Lparen = gen.TokPos
+		gen.Rparen = gen.TokPos
+		if len(gen.Specs) > 0 {
+			gen.Rparen = gen.Specs[len(gen.Specs)-1].End()
+		}
+	}
+	gen.Specs = append(gen.Specs, i)
+	f.Imports = append(f.Imports, i)
+	return i
+}
+
+// removeImport removes the import i from f, along with its declaration when
+// it has no other import.
+func removeImport(f *ast.File, i *ast.ImportSpec) {
+	for n, other := range f.Imports {
+		if other == i {
+			f.Imports = append(f.Imports[:n], f.Imports[n+1:]...)
+			break
+		}
+	}
+	for n, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for s, spec := range gen.Specs {
+			if spec != i {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:s], gen.Specs[s+1:]...)
+			if len(gen.Specs) == 0 {
+				f.Decls = append(f.Decls[:n], f.Decls[n+1:]...)
+			}
+			return
+		}
+	}
+}
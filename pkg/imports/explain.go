@@ -0,0 +1,95 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Match is a group whose pattern matched an import path.
+type Match struct {
+	// Group is the human readable name of the group.
+	Group string
+	// Pattern is the regular expression identifying the group.
+	Pattern string
+}
+
+// Explanation describes how an import path is classified.
+type Explanation struct {
+	// Path is the explained import path.
+	Path string
+	// Name is the name of the import, if any, such as _ or . for the blank
+	// and dot imports.
+	Name string
+	// Group is the group the import is put in.
+	Group string
+	// Placed is whether the import is put in the group of the blank or dot
+	// imports by their placement, regardless of Matches.
+	Placed bool
+	// Matches are all the groups whose pattern matched the import, in order
+	// of precedence. The first one is the group that won, unless Placed. It
+	// is empty when the import is put in the standard group because nothing
+	// matched.
+	Matches []Match
+}
+
+// Explain returns how each import is classified in the file at path, or in
+// the non-test files of the directory at path, according to opts, the
+// options of the directory and the overrides applying to the file. Each of
+// importPaths is an import path, or a name followed by a space and an import
+// path.
+func Explain(path string, importPaths []string, opts *Options) ([]Explanation, error) {
+	if s, err := os.Stat(path); err == nil && s.IsDir() {
+		// a go file of the directory, which is not a test file
+		path = filepath.Join(path, "doc.go")
+	}
+	fm, err := formatterFor(map[*Options]*formatter{}, opts, path)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := fm.overrides.forFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, place := fr.rules, fr.place
+
+	var explanations []Explanation
+	for _, i := range importPaths {
+		e := Explanation{Path: i}
+		spec := &ast.ImportSpec{}
+		if fields := strings.Fields(i); len(fields) == 2 {
+			e.Name, e.Path = fields[0], fields[1]
+			spec.Name = ast.NewIdent(e.Name)
+		}
+		value := strconv.Quote(e.Path)
+		spec.Path = &ast.BasicLit{Kind: token.STRING, Value: value}
+		bucket := place.bucket(r, spec)
+		e.Group = r.describe(bucket)
+		e.Placed = bucket != r.classify(value)
+		for _, re := range r.importRegexp {
+			if re.Regexp.MatchString(value) {
+				e.Matches = append(e.Matches, Match{Group: r.describe(re.Bucket), Pattern: re.Regexp.String()})
+			}
+		}
+		explanations = append(explanations, e)
+	}
+	return explanations, nil
+}
@@ -0,0 +1,138 @@
+package imports
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	opts := &Options{Module: "github.com/openshift/builder", Intermediates: []string{"thirdy.io"}}
+	tests := []struct {
+		path string
+		want Explanation
+	}{
+		{
+			path: "os",
+			want: Explanation{Path: "os", Group: "standard"},
+		},
+		{
+			path: "k8s.io/klog/v2",
+			want: Explanation{
+				Path:  "k8s.io/klog/v2",
+				Group: "kubernetes",
+				Matches: []Match{
+					{Group: "kubernetes", Pattern: "k8s.io"},
+					{Group: "other", Pattern: `[a-zA-Z0-9]+\.[a-zA-Z0-9]+/`},
+				},
+			},
+		},
+		{
+			path: "github.com/openshift/builder/pkg/version",
+			want: Explanation{
+				Path:  "github.com/openshift/builder/pkg/version",
+				Group: "module",
+				Matches: []Match{
+					{Group: "module", Pattern: "github.com/openshift/builder"},
+					{Group: "openshift", Pattern: "github.com/openshift"},
+					{Group: "other", Pattern: `[a-zA-Z0-9]+\.[a-zA-Z0-9]+/`},
+				},
+			},
+		},
+		{
+			path: "thirdy.io/two",
+			want: Explanation{
+				Path:  "thirdy.io/two",
+				Group: `intermediate "thirdy.io"`,
+				Matches: []Match{
+					{Group: `intermediate "thirdy.io"`, Pattern: "thirdy.io"},
+					{Group: "other", Pattern: `[a-zA-Z0-9]+\.[a-zA-Z0-9]+/`},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Explain(".", []string{test.path}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(got[0], test.want) {
+			t.Errorf("path: %s, wanted: %#v, got %#v", test.path, test.want, got)
+		}
+	}
+}
+
+func TestExplainFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "staging", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{
+		Module:       "github.com/openshift/builder",
+		ModuleDir:    dir,
+		BlankImports: PlacementGroup,
+		Overrides: []Override{{
+			Files:  []string{"*_test.go"},
+			Groups: []Group{{Name: "testing", Patterns: []string{"github.com/onsi/"}}},
+		}},
+	}
+	staging := &Options{Module: "k8s.io/api", ModuleDir: filepath.Join(dir, "staging", "api")}
+	opts.ForDir = func(d string) (*Options, error) {
+		if d == staging.ModuleDir {
+			return staging, nil
+		}
+		return opts, nil
+	}
+	other := Match{Group: "other", Pattern: `[a-zA-Z0-9]+\.[a-zA-Z0-9]+/`}
+	tests := []struct {
+		name string
+		path string
+		spec string
+		want Explanation
+	}{
+		{
+			name: "directory",
+			path: dir,
+			spec: "github.com/onsi/gomega",
+			want: Explanation{Path: "github.com/onsi/gomega", Group: "other", Matches: []Match{other}},
+		},
+		{
+			name: "file with an override",
+			path: filepath.Join(dir, "example_test.go"),
+			spec: "github.com/onsi/gomega",
+			want: Explanation{Path: "github.com/onsi/gomega", Group: "testing", Matches: []Match{
+				{Group: "testing", Pattern: "github.com/onsi/"},
+				other,
+			}},
+		},
+		{
+			name: "placed blank import",
+			path: filepath.Join(dir, "example.go"),
+			spec: "_ github.com/lib/pq",
+			want: Explanation{Path: "github.com/lib/pq", Name: "_", Group: BlankGroup, Placed: true, Matches: []Match{other}},
+		},
+		{
+			name: "directory options",
+			path: filepath.Join(dir, "staging", "api", "types.go"),
+			spec: "k8s.io/api/core/v1",
+			want: Explanation{Path: "k8s.io/api/core/v1", Group: "module", Matches: []Match{
+				{Group: "module", Pattern: "k8s.io/api"},
+				{Group: "kubernetes", Pattern: "k8s.io"},
+				other,
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Explain(test.path, []string{test.spec}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], test.want) {
+				t.Errorf("wanted: %#v, got %#v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// fixImports removes the imports of f that are not used, and adds the ones
+// of the packages f refers to without importing them, found in the module,
+// its vendor directory or the standard library. The references no package
+// can be found for are reported.
+func (r *resolver) fixImports(fs *token.FileSet, f *ast.File, path string, reporter *Reporter) {
+	imported := map[string]bool{}
+	dot := false
+	for _, i := range append([]*ast.ImportSpec{}, f.Imports...) {
+		name := r.importName(i)
+		switch {
+		case name == "_":
+			continue
+		case i.Path.Value == `"C"`:
+			imported["C"] = true
+			continue
+		case name == ".":
+			dot = true
+			continue
+		case len(importSelectors(f, name)) > 0:
+			imported[name] = true
+			continue
+		}
+		if importPath, err := strconv.Unquote(i.Path.Value); i.Name == nil && (err != nil || !r.knownName(importPath)) {
+			// the name is only guessed from the import path, so the
+			// import may well be used under its actual name
+			imported[name] = true
+			klog.V(2).Infof("%s: kept import %s, whose package name is unknown", path, i.Path.Value)
+			continue
+		}
+		removeImport(f, i)
+		klog.V(2).Infof("%s: removed unused import %s", path, i.Path.Value)
+	}
+	if dot {
+		// the package qualifiers cannot be told apart from the names of
+		// the dot imports
+		return
+	}
+
+	declared := r.packageDeclarations(f, path)
+	missing := map[string][]*ast.SelectorExpr{}
+	var names []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil && !imported[id.Name] && !declared[id.Name] {
+			if _, ok := missing[id.Name]; !ok {
+				names = append(names, id.Name)
+			}
+			missing[id.Name] = append(missing[id.Name], sel)
+		}
+		return true
+	})
+
+	for _, name := range names {
+		var members []string
+		for _, sel := range missing[name] {
+			members = append(members, sel.Sel.Name)
+		}
+		importPath, ok := r.findPackage(name, members)
+		if !ok {
+			reporter.Report(path, fs.Position(missing[name][0].Pos()).Line, fmt.Sprintf("no package named %s declaring %s found in the module, its vendor directory or the standard library", name, strings.Join(members, ", ")))
+			continue
+		}
+		alias := ""
+		if assumedPackageName(importPath) != name {
+			alias = name
+		}
+		addImport(f, alias, importPath)
+		klog.V(2).Infof("%s: added import %q for %s", path, importPath, name)
+	}
+}
+
+// knownName returns whether the package name of importPath is read from its
+// source rather than guessed.
+func (r *resolver) knownName(importPath string) bool {
+	_, ok := r.packageName(importPath)
+	return ok
+}
+
+// packageDeclarations returns the names declared at the top level of the
+// files of the package of the file at path, f being that file. The test
+// files are only looked at for test files.
+func (r *resolver) packageDeclarations(f *ast.File, path string) map[string]bool {
+	declared := map[string]bool{}
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return declared
+	}
+	test := strings.HasSuffix(path, "_test.go")
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || (!test && strings.HasSuffix(e.Name(), "_test.go")) {
+			continue
+		}
+		decls := r.fileDeclarations(filepath.Join(dir, e.Name()))
+		if decls.pkg != f.Name.Name {
+			continue
+		}
+		for _, name := range decls.names {
+			declared[name] = true
+		}
+	}
+	return declared
+}
+
+// declarations are the package name of a file and the names it declares at
+// the top level.
+type declarations struct {
+	pkg   string
+	names []string
+}
+
+// fileDeclarations returns the declarations of the file at path, caching
+// them since every file of a package needs the ones of the others.
+func (r *resolver) fileDeclarations(path string) declarations {
+	r.mu.Lock()
+	decls, ok := r.declarations[path]
+	r.mu.Unlock()
+	if ok {
+		return decls
+	}
+
+	if f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution); err == nil {
+		decls.pkg = f.Name.Name
+		decls.names = topLevelNames(f, false)
+	}
+	r.mu.Lock()
+	r.declarations[path] = decls
+	r.mu.Unlock()
+	return decls
+}
+
+// topLevelNames returns the names of the constants, variables, types and
+// functions declared by f, only the exported ones when exported is true.
+func topLevelNames(f *ast.File, exported bool) []string {
+	var names []string
+	add := func(id *ast.Ident) {
+		if !exported || id.IsExported() {
+			names = append(names, id.Name)
+		}
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				add(d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name)
+				case *ast.ValueSpec:
+					for _, id := range s.Names {
+						add(id)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// findPackage returns the import path of the package named name declaring
+// all of members, looking at the standard library first, then at the module
+// and its vendor directory. Among several such packages, the one with the
+// shortest import path wins.
+func (r *resolver) findPackage(name string, members []string) (string, bool) {
+	var indexes []packageIndex
+	if r.goroot != "" {
+		indexes = append(indexes, loadIndex(filepath.Join(r.goroot, "src"), "", true))
+	}
+	if r.moduleDir != "" {
+		indexes = append(indexes, loadIndex(r.moduleDir, r.module, false), loadIndex(filepath.Join(r.moduleDir, "vendor"), "", true))
+	}
+	for _, index := range indexes {
+		candidates := append([]string{}, index[name]...)
+		sort.Slice(candidates, func(a, b int) bool {
+			if n, m := strings.Count(candidates[a], "/"), strings.Count(candidates[b], "/"); n != m {
+				return n < m
+			}
+			return candidates[a] < candidates[b]
+		})
+		for _, importPath := range candidates {
+			if dir, ok := r.dir(importPath); ok && r.declaresAll(dir, members) {
+				return importPath, true
+			}
+		}
+	}
+	return "", false
+}
+
+// declaresAll returns whether the package in dir exports all of members.
+func (r *resolver) declaresAll(dir string, members []string) bool {
+	exported := exportedNames(dir)
+	for _, m := range members {
+		if !exported[m] {
+			return false
+		}
+	}
+	return true
+}
+
+// exportedNames returns the names exported by the package in dir.
+func exportedNames(dir string) map[string]bool {
+	exported := map[string]bool{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return exported
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, name := range topLevelNames(f, true) {
+			exported[name] = true
+		}
+	}
+	return exported
+}
+
+// packageIndex maps package names to the import paths of the packages with
+// that name.
+type packageIndex map[string][]string
+
+var (
+	packageIndexesMu sync.Mutex
+	packageIndexes   = map[string]*lazyIndex{}
+)
+
+// lazyIndex is a packageIndex built on first use, once for all the files.
+type lazyIndex struct {
+	once  sync.Once
+	index packageIndex
+}
+
+// loadIndex returns the index of the packages under root, whose import paths
+// are prefix followed by their directory relative to root. The packages
+// under internal directories are left out when skipInternal is true, since
+// they cannot be imported from outside root.
+func loadIndex(root, prefix string, skipInternal bool) packageIndex {
+	packageIndexesMu.Lock()
+	l, ok := packageIndexes[root]
+	if !ok {
+		l = &lazyIndex{}
+		packageIndexes[root] = l
+	}
+	packageIndexesMu.Unlock()
+	l.once.Do(func() {
+		l.index = buildIndex(root, prefix, skipInternal)
+	})
+	return l.index
+}
+
+func buildIndex(root, prefix string, skipInternal bool) packageIndex {
+	index := packageIndex{}
+	filepath.WalkDir(root, func(dir string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if dir != root {
+			name := d.Name()
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || (skipInternal && name == "internal") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+				// a nested module
+				return filepath.SkipDir
+			}
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil
+		}
+		importPath := prefix
+		switch {
+		case rel == "." && prefix == "":
+			return nil
+		case rel == "cmd" && prefix == "":
+			// the go commands of the standard library
+			return filepath.SkipDir
+		case rel != "." && prefix == "":
+			importPath = filepath.ToSlash(rel)
+		case rel != ".":
+			importPath = prefix + "/" + filepath.ToSlash(rel)
+		}
+		if name := readPackageName(dir); name != "" && name != "main" {
+			index[name] = append(index[name], importPath)
+		}
+		return nil
+	})
+	return index
+}
@@ -0,0 +1,131 @@
+package imports
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFixImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]string
+		want    string
+	}{
+		{
+			name: "unused import",
+			sources: map[string]string{"cmd/main.go": `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	str "strings"
+)
+
+func main() { fmt.Println(os.Args) }
+`},
+			want: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() { fmt.Println(os.Args) }
+`,
+		},
+		{
+			name: "missing standard library import",
+			sources: map[string]string{"cmd/main.go": `package main
+
+import (
+	"github.com/example/module/pkg/util"
+)
+
+func main() { fmt.Println(util.Name, rand.Intn(3), filepath.Join("a", "b")) }
+`, "pkg/util/util.go": `package util
+
+const Name = "util"
+`},
+			want: `package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+
+	"github.com/example/module/pkg/util"
+)
+
+func main() { fmt.Println(util.Name, rand.Intn(3), filepath.Join("a", "b")) }
+`,
+		},
+		{
+			name: "missing module and vendored imports",
+			sources: map[string]string{"cmd/main.go": `package main
+
+func main() { cobra.Run(helpers.Name) }
+`, "pkg/util/helpers.go": `package helpers
+
+const Name = "helpers"
+`, "vendor/github.com/spf13/cobra/cobra.go": `package cobra
+
+func Run(string) {}
+`},
+			want: `package main
+
+import (
+	"github.com/spf13/cobra"
+
+	helpers "github.com/example/module/pkg/util"
+)
+
+func main() { cobra.Run(helpers.Name) }
+`,
+		},
+		{
+			name: "declared by another file of the package",
+			sources: map[string]string{"cmd/main.go": `package main
+
+func main() { flags.Parse() }
+`, "cmd/flags.go": `package main
+
+type flagSet struct{}
+
+func (flagSet) Parse() {}
+
+var flags flagSet
+`},
+			want: `package main
+
+func main() { flags.Parse() }
+`,
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		opts := &Options{FixImports: true, Reporter: NewReporter(&out, OutputText)}
+		if got := formatModuleFile(t, test.sources, "cmd/main.go", opts); got != test.want {
+			t.Errorf("test: %s, wanted:\n%s\ngot:\n%s", test.name, test.want, got)
+		}
+		if out.Len() > 0 {
+			t.Errorf("test: %s, wanted no problem, got %q", test.name, out.String())
+		}
+	}
+}
+
+func TestFixImportsNotFound(t *testing.T) {
+	src := `package main
+
+func main() { nowhere.Run() }
+`
+	var out bytes.Buffer
+	opts := &Options{FixImports: true, Reporter: NewReporter(&out, OutputText)}
+	if got := formatFile(t, src, opts); got != src {
+		t.Errorf("wanted the file to be left as is, got:\n%s", got)
+	}
+	if want := ":3: no package named nowhere declaring Run found in the module, its vendor directory or the standard library\n"; !bytes.HasSuffix(out.Bytes(), []byte(want)) {
+		t.Errorf("wanted %q, got %q", want, out.String())
+	}
+}
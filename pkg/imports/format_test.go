@@ -1,7 +1,12 @@
 package imports
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -90,3 +95,247 @@ func TestFormat(t *testing.T) {
 		t.Errorf("Expected %s but got %s", expectedFileContents, resultString)
 	}
 }
+
+// formatFile writes src to a go file, organizes its imports according to
+// opts, and returns the result.
+func formatFile(t *testing.T, src string, opts *Options) string {
+	t.Helper()
+	return formatNamedFile(t, "example.go", src, opts)
+}
+
+// formatNamedFile is formatFile for a file at the slash separated path name
+// of the module directory.
+func formatNamedFile(t *testing.T, name, src string, opts *Options) string {
+	t.Helper()
+	return formatModuleFile(t, map[string]string{name: src}, name, opts)
+}
+
+// formatModuleFile is formatNamedFile for a module holding the sources, by
+// their slash separated paths, name being the one formatted.
+func formatModuleFile(t *testing.T, sources map[string]string, name string, opts *Options) string {
+	t.Helper()
+	dir := t.TempDir()
+	for file, src := range sources {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if opts.Module == "" {
+		opts.Module = "github.com/example/module"
+	}
+	if opts.ModuleDir == "" {
+		opts.ModuleDir = dir
+		defer func() { opts.ModuleDir = "" }()
+	}
+
+	files := make(chan string, 1)
+	files <- path
+	close(files)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	FormatWithOptions(files, &wg, opts)
+	wg.Wait()
+
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestFormatWithOptions(t *testing.T) {
+	src := `package main
+
+import (
+	"os"
+	"github.com/openshift/api"
+	"github.com/example/module/pkg"
+	"k8s.io/api/core/v1"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+`
+	want := `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/openshift/api"
+
+	"github.com/example/module/pkg"
+)
+`
+	if got := formatFile(t, src, &Options{}); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestFormatWithContextCancelled(t *testing.T) {
+	src := `package main
+
+import (
+	"os"
+	"fmt"
+)
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files := make(chan string, 1)
+	files <- path
+	close(files)
+	progress := &Progress{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	FormatWithContext(ctx, files, &wg, &Options{Module: "github.com/example/module", ModuleDir: dir, Progress: progress})
+	wg.Wait()
+
+	if out, err := os.ReadFile(path); err != nil || string(out) != src {
+		t.Errorf("wanted the file to be left as is, got:\n%s", out)
+	}
+	if progress.Processed() != 0 || !reflect.DeepEqual(progress.Left(), []string{path}) {
+		t.Errorf("wanted no file processed and %s left out, got %d processed and %v left out", path, progress.Processed(), progress.Left())
+	}
+}
+
+func TestFormatWithContextProgress(t *testing.T) {
+	sources := map[string]string{
+		"example.go": `package main
+
+import (
+	"os"
+	"fmt"
+)
+`,
+		"generated.go": `// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package main
+
+import (
+	"os"
+	"fmt"
+)
+`,
+	}
+	dir := t.TempDir()
+	files := make(chan string, len(sources))
+	for name, src := range sources {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files <- path
+	}
+	close(files)
+	progress := &Progress{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	FormatWithContext(context.Background(), files, &wg, &Options{Module: "github.com/example/module", ModuleDir: dir, Progress: progress})
+	wg.Wait()
+
+	if progress.Processed() != 1 || progress.Skipped() != 1 || len(progress.Left()) != 0 {
+		t.Errorf("wanted 1 file processed and 1 skipped, got %d processed, %d skipped and %v left out", progress.Processed(), progress.Skipped(), progress.Left())
+	}
+}
+
+func TestFormatWithContextRewriteGenerated(t *testing.T) {
+	src := `// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package main
+
+import (
+	"k8s.io/utils/pointer"
+
+	"os"
+)
+
+var _ = []interface{}{os.Args, pointer.Int32}
+`
+	want := `// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package main
+
+import (
+	pointer "k8s.io/utils/ptr"
+
+	"os"
+)
+
+var _ = []interface{}{os.Args, pointer.Int32}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "zz_generated.deepcopy.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := make(chan string, 1)
+	files <- path
+	close(files)
+	progress := &Progress{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	FormatWithContext(context.Background(), files, &wg, &Options{
+		Module:    "github.com/example/module",
+		ModuleDir: dir,
+		Rewrites:  []Rewrite{{Old: "k8s.io/utils/pointer", New: "k8s.io/utils/ptr"}},
+		Progress:  progress,
+	})
+	wg.Wait()
+
+	if out, err := os.ReadFile(path); err != nil || string(out) != want {
+		t.Errorf("wanted the imports of the generated file to be only rewritten, got:\n%s", out)
+	}
+	if progress.Processed() != 1 {
+		t.Errorf("wanted the generated file to be processed, got %d processed and %d skipped", progress.Processed(), progress.Skipped())
+	}
+}
+
+func TestFormatWithContextInvalidConfig(t *testing.T) {
+	src := `package main
+
+import (
+	"os"
+	"fmt"
+)
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := make(chan string, 1)
+	files <- path
+	close(files)
+	var report bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(1)
+	FormatWithContext(context.Background(), files, &wg, &Options{
+		Reporter: NewReporter(&report, OutputText),
+		ForDir: func(dir string) (*Options, error) {
+			return nil, fmt.Errorf("%s: invalid config", filepath.Join(dir, ".openshift-goimports.yaml"))
+		},
+	})
+	wg.Wait()
+
+	if out, err := os.ReadFile(path); err != nil || string(out) != src {
+		t.Errorf("wanted the file to be left as is, got:\n%s", out)
+	}
+	if want := path + ":0: leaving the file as is: " + filepath.Join(dir, ".openshift-goimports.yaml") + ": invalid config\n"; report.String() != want {
+		t.Errorf("wanted report %q, got %q", want, report.String())
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"path/filepath"
+)
+
+// formatter holds what organizing imports according to some options needs.
+type formatter struct {
+	opts      *Options
+	overrides *overrides
+	aliases   []aliasRule
+	deny      []denyRule
+	resolver  *resolver
+	layering  *restrictions
+	split     *splitter
+}
+
+func newFormatter(opts *Options) (*formatter, error) {
+	overrides, err := newOverrides(opts)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := newAliasRules(opts.Aliases)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := newDenyRules(opts.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &formatter{
+		opts:      opts,
+		overrides: overrides,
+		aliases:   aliases,
+		deny:      deny,
+		resolver:  newResolver(opts),
+		layering:  newRestrictions(opts.ModuleDir),
+		split:     newSplitter(opts),
+	}, nil
+}
+
+// CheckOptions returns the first mistake of the configuration of opts, such
+// as an invalid pattern, found before any file is organized.
+func CheckOptions(opts *Options) error {
+	if _, err := newOverrides(opts); err != nil {
+		return err
+	}
+	if _, err := newAliasRules(opts.Aliases); err != nil {
+		return err
+	}
+	_, err := newDenyRules(opts.Deny)
+	return err
+}
+
+// formatterFor returns the formatter of the file at path, using the options
+// of its directory when opts has ForDir, and caching formatters by options.
+func formatterFor(formatters map[*Options]*formatter, opts *Options, path string) (*formatter, error) {
+	if opts.ForDir != nil {
+		dirOpts, err := opts.ForDir(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		opts = dirOpts
+	}
+	if fm, ok := formatters[opts]; ok {
+		return fm, nil
+	}
+	fm, err := newFormatter(opts)
+	if err != nil {
+		return nil, err
+	}
+	formatters[opts] = fm
+	return fm, nil
+}
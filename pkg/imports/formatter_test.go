@@ -0,0 +1,84 @@
+package imports
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatterFor(t *testing.T) {
+	src := `package example
+
+import (
+	"os"
+	"github.com/thirdy/one"
+	"github.com/spf13/cobra"
+)
+`
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "directory without config",
+			file: "pkg/example.go",
+			want: `package example
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thirdy/one"
+)
+`,
+		},
+		{
+			name: "directory with config",
+			file: "staging/one/example.go",
+			want: `package example
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/thirdy/one"
+)
+`,
+		},
+	}
+
+	for _, test := range tests {
+		opts := &Options{}
+		staging := &Options{Module: "github.com/example/module", Intermediates: []string{"github.com/thirdy/one"}}
+		opts.ForDir = func(dir string) (*Options, error) {
+			if filepath.Base(dir) == "one" {
+				staging.ModuleDir = dir
+				return staging, nil
+			}
+			return opts, nil
+		}
+		got := formatNamedFile(t, test.file, src, opts)
+		if got != test.want {
+			t.Errorf("test: %s, wanted:\n%s\ngot:\n%s", test.name, test.want, got)
+		}
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "invalid intermediate", opts: Options{Module: "github.com/example/module", Intermediates: []string{"github.com/(foo"}}},
+		{name: "invalid module", opts: Options{Module: "github.com/(example"}},
+	}
+	for _, tt := range tests {
+		if err := CheckOptions(&tt.opts); err == nil {
+			t.Errorf("test: %s, wanted CheckOptions to report the invalid pattern", tt.name)
+		}
+		if _, err := newFormatter(&tt.opts); err == nil {
+			t.Errorf("test: %s, wanted newFormatter to report the invalid pattern", tt.name)
+		}
+	}
+}
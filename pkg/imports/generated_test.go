@@ -0,0 +1,60 @@
+package imports
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		generated bool
+	}{
+		{
+			name:      "deepcopy header",
+			src:       "//go:build !ignore_autogenerated\n\n// Code generated by deepcopy-gen. DO NOT EDIT.\n\npackage v1\n",
+			generated: true,
+		},
+		{
+			name:      "after license",
+			src:       "/*\nCopyright.\n*/\n\n// Code generated by client-gen. DO NOT EDIT.\n\npackage versioned\n",
+			generated: true,
+		},
+		{
+			name:      "doc comment",
+			src:       "// Code generated by go-bindata. DO NOT EDIT.\n// sources:\n// assets/a.yaml\npackage bindata\n",
+			generated: true,
+		},
+		{
+			name:      "not generated",
+			src:       "// Package main does things.\npackage main\n",
+			generated: false,
+		},
+		{
+			name:      "missing period",
+			src:       "// Code generated by hand. DO NOT EDIT\npackage main\n",
+			generated: false,
+		},
+		{
+			name:      "block comment",
+			src:       "/* Code generated by hand. DO NOT EDIT. */\npackage main\n",
+			generated: false,
+		},
+		{
+			name:      "after package clause",
+			src:       "package main\n\n// Code generated by hand. DO NOT EDIT.\n",
+			generated: false,
+		},
+	}
+	for _, tt := range tests {
+		f, err := parser.ParseFile(token.NewFileSet(), "example.go", tt.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tt.name, err)
+		}
+		if got := isGenerated(f); got != tt.generated {
+			t.Errorf("%s: wanted %v, got %v", tt.name, tt.generated, got)
+		}
+	}
+}
@@ -18,16 +18,15 @@ package imports
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"go/ast"
-	"go/format"
 	"go/parser"
 	"go/printer"
 	"go/scanner"
 	"go/token"
 	"io"
-	"io/ioutil"
 	"os"
 	"regexp"
 	"sort"
@@ -35,7 +34,11 @@ import (
 	"strings"
 	"sync"
 
+	"golang.org/x/mod/module"
+
 	"k8s.io/klog/v2"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/util"
 )
 
 type ImportRegexp struct {
@@ -98,135 +101,400 @@ func addSpaces(r io.Reader, breaks []string) ([]byte, error) {
 	return out.Bytes(), nil
 }
 
-// Format takes a channel of file paths and formats the files imports
-func Format(files chan string, wg *sync.WaitGroup, intermediatePatternList []string, modulePtr *string, dry *bool, list *bool) {
-	defer wg.Done()
-	importRegexp := []ImportRegexp{
-		{Bucket: "module", Regexp: regexp.MustCompile(*modulePtr)},
+// Options configures how Format organizes the imports of each file.
+type Options struct {
+	// Module is the path of the go module being organized.
+	Module string
+	// Intermediates are patterns of modules to put between openshift and module.
+	Intermediates []string
+	// ModuleDir is the directory of Module, where its go.mod file is.
+	ModuleDir string
+	// Requirements are the modules required by the go.mod file of Module,
+	// used to tell which module an import belongs to.
+	Requirements []module.Version
+	// Dry reports files whose imports are not sorted without modifying them.
+	Dry bool
+	// List prints files whose imports are not sorted without modifying them.
+	List bool
+	// Output selects how files whose imports are not sorted are reported.
+	Output string
+	// Aliases are the aliases the imports of some packages must use.
+	Aliases []AliasRule
+	// FixAliases renames the imports not using the alias required by Aliases
+	// instead of reporting them.
+	FixAliases bool
+	// RemoveRedundantAliases drops the aliases that are the same as the name
+	// of the imported package.
+	RemoveRedundantAliases bool
+	// FixImports removes the unused imports and adds the missing ones, found
+	// in the module, its vendor directory or the standard library.
+	FixImports bool
+	// AliasConflicts gives the imports whose package names are the same an
+	// alias built from AliasTemplate.
+	AliasConflicts bool
+	// AliasTemplate is the template of the aliases given to conflicting
+	// imports, DefaultAliasTemplate when empty.
+	AliasTemplate string
+	// Deny are the packages that must not be imported.
+	Deny []DenyRule
+	// MigrateIoutil rewrites the uses of the deprecated io/ioutil package with
+	// their os and io equivalents.
+	MigrateIoutil bool
+	// CheckRestrictions reports the imports forbidden by the import
+	// restrictions files found from the directory of each file up to
+	// ModuleDir.
+	CheckRestrictions bool
+	// Rewrites move imports to other paths. When set, only the files with
+	// imports moved are organized, and generated files have their imports
+	// moved even without IncludeGenerated.
+	Rewrites []Rewrite
+	// PreserveSubgroups keeps the runs of imports separated by blank lines
+	// whose imports all belong to the same group as sub-groups of that group,
+	// after its other imports, only sorting the imports of each.
+	PreserveSubgroups bool
+	// SplitBy splits the SplitGroups into sub-groups, one of SplitModes. Groups
+	// are not split when empty.
+	SplitBy string
+	// SplitGroups are the names of the groups split by SplitBy, or the
+	// patterns of intermediate ones, DefaultSplitGroups when empty.
+	SplitGroups []string
+	// BlankImports and DotImports are the placements of the blank and dot
+	// imports, one of Placements. They are sorted with the other imports of
+	// their group when empty. The ones placed first or last are separated
+	// from the other imports of their group by a blank line, since gofmt sorts
+	// each run of imports.
+	BlankImports string
+	DotImports   string
+	// Sort orders the imports of each group by import path, in the byte order
+	// as gofmt does when nil. gofmt then sorts each run of imports not
+	// separated by a blank line again, so files sorted otherwise are
+	// reported by gofmt -l when their orders differ.
+	Sort Less
+	// Overrides change how the imports of the files they match are organized.
+	Overrides []Override
+	// ForDir, when set, returns the options of the files of a directory, for
+	// example from the config files found in it and its parents. The files
+	// are organized according to the options it returns instead, which must
+	// be the same for directories sharing them.
+	ForDir func(dir string) (*Options, error)
+	// IncludeGenerated organizes the files with a generated code header too.
+	IncludeGenerated bool
+	// Progress, when set, counts the files processed and records the ones
+	// left out when Format is cancelled.
+	Progress *Progress
+	// Reporter receives the problems found in each file. When nil, problems
+	// are only logged.
+	Reporter *Reporter
+}
+
+// rules holds the compiled classification of imports into groups.
+type rules struct {
+	importRegexp []ImportRegexp
+	importOrder  []string
+	intermediate map[string]string
+	// custom is the number of regexps of the groups added by overrides,
+	// checked first.
+	custom int
+}
+
+func newRules(module string, intermediatePatternList []string) (*rules, error) {
+	moduleRe, err := regexp.Compile(module)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module %q: %v", module, err)
+	}
+	r := &rules{
+		importRegexp: []ImportRegexp{
+			{Bucket: "module", Regexp: moduleRe},
+		},
+		importOrder:  append([]string{}, importOrderPrefix...),
+		intermediate: map[string]string{},
 	}
-	importOrder := importOrderPrefix
 	for idx, intermediatePattern := range intermediatePatternList {
-		imRe := regexp.MustCompile(intermediatePattern)
+		imRe, err := regexp.Compile(intermediatePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intermediate pattern %q: %v", intermediatePattern, err)
+		}
 		bucketName := fmt.Sprintf("intermediate%d", idx)
-		importRegexp = append(importRegexp, ImportRegexp{Bucket: bucketName, Regexp: imRe})
-		importOrder = append(importOrder, bucketName)
+		r.importRegexp = append(r.importRegexp, ImportRegexp{Bucket: bucketName, Regexp: imRe})
+		r.importOrder = append(r.importOrder, bucketName)
+		r.intermediate[bucketName] = intermediatePattern
 	}
-	importRegexp = append(importRegexp, []ImportRegexp{
+	r.importRegexp = append(r.importRegexp, []ImportRegexp{
 		{Bucket: "kubernetes", Regexp: regexp.MustCompile("k8s.io")},
 		{Bucket: "openshift", Regexp: regexp.MustCompile("github.com/openshift")},
 		{Bucket: "other", Regexp: regexp.MustCompile("[a-zA-Z0-9]+\\.[a-zA-Z0-9]+/")},
 	}...)
-	importOrder = append(importOrder, "module")
+	r.importOrder = append(r.importOrder, "module")
+	return r, nil
+}
+
+// classify returns the bucket of the quoted import path value.
+func (r *rules) classify(value string) string {
+	for _, re := range r.importRegexp {
+		if re.Regexp.MatchString(value) {
+			return re.Bucket
+		}
+	}
+	return "standard"
+}
+
+// order returns the position of bucket in the sorted import block.
+func (r *rules) order(bucket string) int {
+	for idx, b := range r.importOrder {
+		if b == bucket {
+			return idx
+		}
+	}
+	return len(r.importOrder)
+}
+
+// describe returns a human readable name for bucket.
+func (r *rules) describe(bucket string) string {
+	if pattern, ok := r.intermediate[bucket]; ok {
+		return fmt.Sprintf("intermediate %q", pattern)
+	}
+	return bucket
+}
+
+// Format takes a channel of file paths and formats the files imports
+func Format(files chan string, wg *sync.WaitGroup, intermediatePatternList []string, modulePtr *string, dry *bool, list *bool) {
+	FormatWithOptions(files, wg, &Options{
+		Module:        *modulePtr,
+		Intermediates: intermediatePatternList,
+		Dry:           *dry,
+		List:          *list,
+	})
+}
+
+// FormatWithOptions takes a channel of file paths and formats the files
+// imports according to opts
+func FormatWithOptions(files chan string, wg *sync.WaitGroup, opts *Options) {
+	FormatWithContext(context.Background(), files, wg, opts)
+}
+
+// FormatWithContext is FormatWithOptions stopping when ctx is done: the file
+// being processed is finished, and the files received afterwards are left
+// out, until files is closed.
+func FormatWithContext(ctx context.Context, files chan string, wg *sync.WaitGroup, opts *Options) {
+	defer wg.Done()
+	formatters := map[*Options]*formatter{}
 
 	for path := range files {
 		if len(path) == 0 {
 			continue
 		}
-		klog.V(2).Infof("Processing %s", path)
+		if ctx.Err() != nil {
+			opts.Progress.leave(path)
+			continue
+		}
+		if formatPath(formatters, opts, path) {
+			opts.Progress.finish()
+		} else {
+			opts.Progress.skip()
+		}
+	}
+}
 
-		info, err := os.Stat(path)
-		if err != nil {
-			klog.Errorf("%#v", err)
+// formatPath organizes the imports of the file at path according to opts, or
+// the options of its directory when opts has ForDir. It returns false when
+// the file is skipped: generated, ignored, or not importing any package to
+// rewrite.
+func formatPath(formatters map[*Options]*formatter, opts *Options, path string) bool {
+	klog.V(2).Infof("Processing %s", path)
+	fm, err := formatterFor(formatters, opts, path)
+	if err != nil {
+		opts.Reporter.Report(path, 0, fmt.Sprintf("leaving the file as is: %v", err))
+		return true
+	}
+	fileRules, err := fm.overrides.forFile(path)
+	if err != nil {
+		fm.opts.Reporter.Report(path, 0, fmt.Sprintf("leaving the file as is: %v", err))
+		return true
+	}
+	r, place := fileRules.rules, fileRules.place
+
+	var breaks []string
+	fs := token.NewFileSet()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		klog.Errorf("%#v", err)
+	}
+	f, err := parser.ParseFile(fs, path, contents, parser.ParseComments)
+	if err != nil {
+		var scannerErrorList scanner.ErrorList
+		if errors.As(err, &scannerErrorList) {
+			for _, err := range scannerErrorList {
+				klog.Errorf("%v", err)
+			}
+			os.Exit(1)
+		} else {
+			klog.Errorf("%v", err)
+			os.Exit(1)
 		}
-		oldModTime := info.ModTime()
+	}
 
-		importGroups := map[string][]ast.ImportSpec{
-			"standard":   {},
-			"other":      {},
-			"kubernetes": {},
-			"openshift":  {},
-			"module":     {},
+	if !fm.opts.IncludeGenerated && isGenerated(f) {
+		if len(fm.opts.Rewrites) > 0 {
+			return fm.rewriteGenerated(fs, f, path, contents)
 		}
-		var breaks []string
-		fs := token.NewFileSet()
-		contents, err := ioutil.ReadFile(path)
-		if err != nil {
-			klog.Errorf("%#v", err)
+		klog.V(2).Infof("Skipping generated file %s", path)
+		return false
+	}
+	if isIgnored(f) {
+		klog.V(2).Infof("Skipping %s, ignored by %s", path, IgnoreDirective)
+		return false
+	}
+	frozen, frozenSpecs := frozenGroups(fs, f)
+	if len(fm.opts.Rewrites) > 0 && !fm.resolver.rewriteImports(f, path, fm.opts.Rewrites) {
+		return false
+	}
+	if duplicates := fm.resolver.dedupeImports(f, path); len(duplicates) > 0 {
+		for _, d := range duplicates {
+			fm.opts.Reporter.Report(path, fs.Position(d.spec.Pos()).Line, d.message(fs, fm.resolver))
 		}
-		f, err := parser.ParseFile(fs, path, contents, parser.ParseComments)
-		if err != nil {
-			var scannerErrorList scanner.ErrorList
-			if errors.As(err, &scannerErrorList) {
-				for _, err := range scannerErrorList {
-					klog.Errorf("%v", err)
-				}
-				os.Exit(1)
-			} else {
-				klog.Errorf("%v", err)
-				os.Exit(1)
-			}
+		return true
+	}
+	if fm.opts.MigrateIoutil {
+		fm.resolver.migrateIoutil(fs, f, path, fm.opts.Reporter)
+	}
+	if fm.opts.RemoveRedundantAliases {
+		fm.resolver.removeRedundantAliases(f, path)
+	}
+	if fm.opts.FixImports {
+		fm.resolver.fixImports(fs, f, path, fm.opts.Reporter)
+	}
+	if fm.opts.AliasConflicts {
+		template := fm.opts.AliasTemplate
+		if template == "" {
+			template = DefaultAliasTemplate
+		}
+		fm.resolver.aliasConflicts(fs, f, path, template, fm.opts.Reporter)
+	}
+	if len(fm.aliases) > 0 {
+		fm.resolver.checkAliases(fs, f, path, fm.aliases, fm.opts.FixAliases, fm.opts.Reporter)
+	}
+
+	if len(fm.deny) > 0 {
+		checkDenied(fs, f, path, fm.deny, fm.opts.Reporter)
+	}
+	if fm.opts.CheckRestrictions {
+		if err := fm.layering.checkRestrictions(fs, f, path, fm.opts.Reporter); err != nil {
+			klog.Errorf("%v", err)
+			os.Exit(1)
 		}
+	}
+
+	var unsorted []unsortedBlock
+	if fm.opts.Output == OutputGitHub {
+		unsorted = r.unsortedBlocks(fs, f, contents, layout{frozen: frozenSpecs, subgroups: fm.opts.PreserveSubgroups, split: fm.split, place: place, less: fm.opts.Sort})
+	}
+
+	var subgroups map[*ast.BasicLit]int
+	if fm.opts.PreserveSubgroups {
+		subgroups = r.subgroups(fs, f, frozenSpecs, place)
+	}
+	subgroupOf := func(group string, i ast.ImportSpec) subgroup {
+		return subgroup{rank: place.rank(&i), preserved: subgroups[i.Path], split: fm.split.key(r, group, i.Path.Value)}
+	}
 
-		for _, i := range f.Imports {
-			if len(i.Path.Value) == 0 {
+	// the imports of each import declaration are organized on their own, so
+	// that none moves to another declaration
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		importGroups := map[string][]ast.ImportSpec{}
+		for _, spec := range gen.Specs {
+			i := spec.(*ast.ImportSpec)
+			if len(i.Path.Value) == 0 || frozenSpecs[i] {
 				continue
 			}
-			found := false
-			for _, r := range importRegexp {
-				if r.Regexp.MatchString(i.Path.Value) {
-					importGroups[r.Bucket] = append(importGroups[r.Bucket], *i)
-					found = true
-					klog.V(3).InfoS("Import classified", "file", path, "import", i.Path.Value, "bucket", r.Bucket)
-					break
-				}
-			}
-			if !found {
-				importGroups["standard"] = append(importGroups["standard"], *i)
-			}
+			bucket := place.bucket(r, i)
+			importGroups[bucket] = append(importGroups[bucket], *i)
+			klog.V(3).InfoS("Import classified", "file", path, "import", i.Path.Value, "bucket", bucket)
 		}
 
-		for _, decl := range f.Decls {
-			gen, ok := decl.(*ast.GenDecl)
-			if ok && gen.Tok == token.IMPORT {
-				gen.Specs = []ast.Spec{}
-				for _, group := range importOrder {
-					sort.Sort(byPathValue(importGroups[group]))
-					for n := range importGroups[group] {
-						importGroups[group][n].EndPos = 0
-						importGroups[group][n].Path.ValuePos = 0
-						if importGroups[group][n].Name != nil {
-							importGroups[group][n].Name.NamePos = 0
-						}
-						gen.Specs = append(gen.Specs, &importGroups[group][n])
-						if n == 0 && group != importOrder[0] {
-							newstr, err := strconv.Unquote(importGroups[group][n].Path.Value)
-							if err != nil {
-								klog.Errorf("%#v", err)
-							}
-							breaks = append(breaks, newstr)
-						}
+		gen.Specs = []ast.Spec{}
+		for _, group := range r.importOrder {
+			sortSpecs(importGroups[group], fm.opts.Sort)
+			if subgroups != nil || fm.split != nil || place.active() {
+				specs, group := importGroups[group], group
+				sort.SliceStable(specs, func(a, b int) bool { return subgroupOf(group, specs[a]).less(subgroupOf(group, specs[b])) })
+			}
+			for n := range importGroups[group] {
+				importGroups[group][n].EndPos = 0
+				importGroups[group][n].Path.ValuePos = 0
+				if importGroups[group][n].Name != nil {
+					importGroups[group][n].Name.NamePos = 0
+				}
+				gen.Specs = append(gen.Specs, &importGroups[group][n])
+				// the first import of a declaration needs no blank line
+				// before it
+				if (n == 0 && len(gen.Specs) > 1) || (n > 0 && subgroupOf(group, importGroups[group][n]) != subgroupOf(group, importGroups[group][n-1])) {
+					newstr, err := strconv.Unquote(importGroups[group][n].Path.Value)
+					if err != nil {
+						klog.Errorf("%#v", err)
 					}
+					breaks = append(breaks, newstr)
 				}
 			}
 		}
+	}
 
-		printerMode := printer.TabIndent
+	removeComments(fs, f, frozen)
 
-		printConfig := &printer.Config{Mode: printerMode, Tabwidth: 4}
+	printerMode := printer.TabIndent
 
-		var buf bytes.Buffer
-		if err = printConfig.Fprint(&buf, fs, f); err != nil {
-			klog.Errorf("%#v", err)
+	printConfig := &printer.Config{Mode: printerMode, Tabwidth: 4}
+
+	var buf bytes.Buffer
+	if err = printConfig.Fprint(&buf, fs, f); err != nil {
+		klog.Errorf("%#v", err)
+	}
+	out, err := addSpaces(bytes.NewReader(buf.Bytes()), breaks)
+	if len(frozen) > 0 {
+		out = insertFrozen(out, f, contents, frozen)
+	}
+	if err == nil {
+		out, err = formatSource(out, fm.opts.Sort)
+	}
+	if err == nil && !bytes.Equal(contents, out) {
+		err = verifyOutput(f, out)
+	}
+	if err != nil {
+		fm.opts.Reporter.Report(path, firstImportLine(fs, f), fmt.Sprintf("internal error, leaving the file as is: %v", err))
+		return true
+	}
+	fm.update(fs, f, path, contents, out, unsorted)
+	return true
+}
+
+// update reports or writes out the organized contents of the file at path,
+// as the output options of fm ask.
+func (fm *formatter) update(fs *token.FileSet, f *ast.File, path string, contents, out []byte, unsorted []unsortedBlock) {
+	if bytes.Equal(contents, out) {
+		return
+	}
+	if fm.opts.Output == OutputGitHub {
+		if len(unsorted) == 0 {
+			unsorted = append(unsorted, unsortedBlock{line: firstImportLine(fs, f), message: "imports are not formatted"})
 		}
-		out, err := addSpaces(bytes.NewReader(buf.Bytes()), breaks)
-		out, err = format.Source(out)
-		if bytes.Compare(contents, out) != 0 {
-			if *dry {
-				klog.Infof("%s is not sorted", path)
-			} else if *list {
-				fmt.Printf("%s is not sorted \n", path)
-			} else {
-				info, err := os.Stat(path)
-				if !info.ModTime().Equal(oldModTime) {
-					klog.Warningf("%s got changed while formatting, cowardly refusing to overwrite", path)
-					continue
-				}
-				if err = ioutil.WriteFile(path, out, info.Mode()); err != nil {
-					klog.Errorf("%#v", err)
-				}
-				klog.Infof("%s updated", path)
-			}
+		for _, u := range unsorted {
+			fm.opts.Reporter.Report(path, u.line, u.message)
 		}
-
+	} else if fm.opts.Dry {
+		klog.Infof("%s is not sorted", path)
+	} else if fm.opts.List {
+		fmt.Printf("%s is not sorted \n", path)
+	} else {
+		if err := util.WriteFile(path, contents, out); errors.Is(err, util.ErrChanged) {
+			klog.Warningf("%s got changed while formatting, cowardly refusing to overwrite", path)
+			return
+		} else if err != nil {
+			klog.Errorf("%#v", err)
+			return
+		}
+		klog.Infof("%s updated", path)
 	}
 }
@@ -0,0 +1,98 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"k8s.io/klog/v2"
+)
+
+// ioutilReplacements maps the members of the deprecated io/ioutil package to
+// their equivalents in os and io. ioutil.ReadDir is left out since os.ReadDir
+// returns []os.DirEntry instead of []os.FileInfo.
+var ioutilReplacements = map[string]struct{ pkg, name string }{
+	"ReadFile":  {"os", "ReadFile"},
+	"WriteFile": {"os", "WriteFile"},
+	"TempDir":   {"os", "MkdirTemp"},
+	"TempFile":  {"os", "CreateTemp"},
+	"NopCloser": {"io", "NopCloser"},
+	"Discard":   {"io", "Discard"},
+	"ReadAll":   {"io", "ReadAll"},
+}
+
+// migrateIoutil rewrites the uses of io/ioutil in f with their os and io
+// equivalents, importing those and removing io/ioutil when it is no longer
+// used. The uses that cannot be rewritten as is are reported.
+func (r *resolver) migrateIoutil(fs *token.FileSet, f *ast.File, path string, reporter *Reporter) {
+	ioutil := findImport(f, "io/ioutil")
+	if ioutil == nil {
+		return
+	}
+	name := r.importName(ioutil)
+	if name == "_" || name == "." {
+		return
+	}
+
+	// The name of each replacement package in the file, or an empty string when
+	// it cannot be used because a declaration of the file shadows it.
+	names := map[string]string{}
+	for _, pkg := range []string{"os", "io"} {
+		names[pkg] = pkg
+		if i := findImport(f, pkg); i != nil {
+			names[pkg] = r.importName(i)
+		}
+		for _, other := range f.Imports {
+			if other.Path.Value != fmt.Sprintf("%q", pkg) && r.importName(other) == names[pkg] {
+				names[pkg] = ""
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == names[pkg] && id.Obj != nil {
+				names[pkg] = ""
+			}
+			return names[pkg] != ""
+		})
+	}
+
+	remaining := 0
+	for _, sel := range importSelectors(f, name) {
+		line := fs.Position(sel.Pos()).Line
+		replacement, ok := ioutilReplacements[sel.Sel.Name]
+		switch {
+		case sel.Sel.Name == "ReadDir":
+			reporter.Report(path, line, fmt.Sprintf("%s.ReadDir returns []os.FileInfo and os.ReadDir returns []os.DirEntry, migrate it manually", name))
+		case !ok:
+			reporter.Report(path, line, fmt.Sprintf("%s.%s has no known replacement", name, sel.Sel.Name))
+		case names[replacement.pkg] == "":
+			reporter.Report(path, line, fmt.Sprintf("cannot replace %s.%s with %s.%s, %s is already declared in the file", name, sel.Sel.Name, replacement.pkg, replacement.name, replacement.pkg))
+		default:
+			if findImport(f, replacement.pkg) == nil {
+				addImport(f, "", replacement.pkg)
+			}
+			klog.V(2).Infof("%s:%d: replaced %s.%s with %s.%s", path, line, name, sel.Sel.Name, replacement.pkg, replacement.name)
+			sel.X.(*ast.Ident).Name = names[replacement.pkg]
+			sel.Sel.Name = replacement.name
+			continue
+		}
+		remaining++
+	}
+	if remaining == 0 {
+		removeImport(f, ioutil)
+	}
+}
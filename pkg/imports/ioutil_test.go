@@ -0,0 +1,129 @@
+package imports
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestMigrateIoutil(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		want   string
+		report string
+	}{
+		{
+			name: "all replaced",
+			src: `package main
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+func main() {
+	b, _ := ioutil.ReadFile("in")
+	_ = ioutil.WriteFile("out", b, 0644)
+	d, _ := ioutil.TempDir("", "dir")
+	f, _ := ioutil.TempFile(d, "file")
+	_, _ = ioutil.ReadAll(ioutil.NopCloser(f))
+	fmt.Fprint(ioutil.Discard, d)
+}
+`,
+			want: `package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	b, _ := os.ReadFile("in")
+	_ = os.WriteFile("out", b, 0644)
+	d, _ := os.MkdirTemp("", "dir")
+	f, _ := os.CreateTemp(d, "file")
+	_, _ = io.ReadAll(io.NopCloser(f))
+	fmt.Fprint(io.Discard, d)
+}
+`,
+		},
+		{
+			name: "read dir flagged",
+			src: `package main
+
+import (
+	goos "os"
+	"io/ioutil"
+)
+
+func main() {
+	infos, _ := ioutil.ReadDir(".")
+	b, _ := ioutil.ReadFile(infos[0].Name())
+	goos.Stdout.Write(b)
+}
+`,
+			want: `package main
+
+import (
+	"io/ioutil"
+	goos "os"
+)
+
+func main() {
+	infos, _ := ioutil.ReadDir(".")
+	b, _ := goos.ReadFile(infos[0].Name())
+	goos.Stdout.Write(b)
+}
+`,
+			report: "example.go:9: ioutil.ReadDir returns []os.FileInfo and os.ReadDir returns []os.DirEntry, migrate it manually\n",
+		},
+		{
+			name: "shadowed package",
+			src: `package main
+
+import "io/ioutil"
+
+func main() {
+	io := 1
+	_, _ = ioutil.ReadAll(nil)
+	_ = io
+}
+`,
+			want: `package main
+
+import "io/ioutil"
+
+func main() {
+	io := 1
+	_, _ = ioutil.ReadAll(nil)
+	_ = io
+}
+`,
+			report: "example.go:7: cannot replace ioutil.ReadAll with io.ReadAll, io is already declared in the file\n",
+		},
+	}
+
+	for _, test := range tests {
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, "example.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("test: %s, failed to parse: %v", test.name, err)
+		}
+		var report bytes.Buffer
+		newResolver(&Options{}).migrateIoutil(fs, f, "example.go", NewReporter(&report, OutputText))
+		var out bytes.Buffer
+		if err := format.Node(&out, fs, f); err != nil {
+			t.Fatalf("test: %s, failed to print: %v", test.name, err)
+		}
+		if out.String() != test.want {
+			t.Errorf("test: %s, wanted: %s, got %s", test.name, test.want, out.String())
+		}
+		if report.String() != test.report {
+			t.Errorf("test: %s, wanted report: %q, got %q", test.name, test.report, report.String())
+		}
+	}
+}
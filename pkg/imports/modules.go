@@ -0,0 +1,95 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// StandardModule is the module reported for standard library packages.
+const StandardModule = "std"
+
+// hostsWithOrganizations are hosts whose module paths are host/organization/repository.
+var hostsWithOrganizations = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+	"golang.org":    true,
+}
+
+// moduleOf returns the path of the module providing the package importPath.
+// The module being organized and its requirements are preferred, the longest
+// matching path winning, otherwise the module is guessed from the shape of the
+// import path.
+func moduleOf(importPath, mod string, requirements []module.Version) string {
+	best := ""
+	if hasPathPrefix(importPath, mod) {
+		best = mod
+	}
+	for _, r := range requirements {
+		if len(r.Path) > len(best) && hasPathPrefix(importPath, r.Path) {
+			best = r.Path
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	elems := strings.Split(importPath, "/")
+	if !strings.Contains(elems[0], ".") {
+		return StandardModule
+	}
+	n := 2
+	if hostsWithOrganizations[elems[0]] {
+		n = 3
+	}
+	if len(elems) <= n {
+		return importPath
+	}
+	if isMajorVersion(elems[n]) {
+		n++
+	}
+	return strings.Join(elems[:n], "/")
+}
+
+// hasPathPrefix returns whether importPath is prefix or a package under it.
+func hasPathPrefix(importPath, prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
+
+// matchPackage returns whether importPath matches pattern, which is either an
+// import path or an import path followed by /... to match the packages under it.
+func matchPackage(importPath, pattern string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return hasPathPrefix(importPath, prefix)
+	}
+	return importPath == pattern
+}
+
+// isMajorVersion returns whether elem is a major version suffix such as v2.
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	n, err := strconv.Atoi(elem[1:])
+	return err == nil && n >= 2 && elem[1] != '0'
+}
@@ -0,0 +1,38 @@
+package imports
+
+import (
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestModuleOf(t *testing.T) {
+	requirements := []module.Version{
+		{Path: "k8s.io/api", Version: "v0.26.0"},
+		{Path: "k8s.io/apimachinery", Version: "v0.26.0"},
+		{Path: "github.com/openshift/library-go", Version: "v0.0.0-20230120214501-9bc305884fcb"},
+		{Path: "github.com/openshift/library-go/v2", Version: "v2.0.0"},
+	}
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{"os", StandardModule},
+		{"encoding/json", StandardModule},
+		{"github.com/example-org/example-repo/pkg/util", "github.com/example-org/example-repo"},
+		{"k8s.io/api/core/v1", "k8s.io/api"},
+		{"k8s.io/apimachinery/pkg/apis/meta/v1", "k8s.io/apimachinery"},
+		{"github.com/openshift/library-go/pkg/operator/events", "github.com/openshift/library-go"},
+		{"github.com/openshift/library-go/v2/pkg/operator", "github.com/openshift/library-go/v2"},
+		{"github.com/openshift/library-gopher", "github.com/openshift/library-gopher"},
+		{"github.com/onsi/ginkgo/v2/dsl/core", "github.com/onsi/ginkgo/v2"},
+		{"go.uber.org/zap/zapcore", "go.uber.org/zap"},
+		{"golang.org/x/mod/modfile", "golang.org/x/mod"},
+	}
+
+	for _, test := range tests {
+		if got := moduleOf(test.importPath, "github.com/example-org/example-repo", requirements); got != test.want {
+			t.Errorf("import: %s, wanted: %s, got %s", test.importPath, test.want, got)
+		}
+	}
+}
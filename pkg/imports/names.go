@@ -0,0 +1,122 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"strings"
+	"unicode"
+
+	"k8s.io/klog/v2"
+)
+
+// assumedPackageName returns the package name of importPath guessed from the
+// import path, the same way goimports does: the last element, or the one
+// before it when it is a major version, without a go- prefix and cut at the
+// first character that is not valid in an identifier.
+func assumedPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if isMajorVersion(base) {
+		if dir := path.Dir(importPath); dir != "." {
+			base = path.Base(dir)
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// renameImport makes f refer to the import i by name, rewriting every use of
+// its current name. It refuses to when name is already used in f.
+func (r *resolver) renameImport(f *ast.File, i *ast.ImportSpec, name string) error {
+	old := r.importName(i)
+	if old == name {
+		return nil
+	}
+	if old == "_" || old == "." || old == "" {
+		return fmt.Errorf("cannot rename import %s", i.Path.Value)
+	}
+	for _, other := range f.Imports {
+		if other != i && r.importName(other) == name {
+			return fmt.Errorf("cannot rename import %s to %s, %s is already imported as %s", i.Path.Value, name, other.Path.Value, name)
+		}
+	}
+	used := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && (i.Name == nil || id != i.Name) {
+			used = true
+		}
+		return !used
+	})
+	if used {
+		return fmt.Errorf("cannot rename import %s to %s, %s is already used in the file", i.Path.Value, name, name)
+	}
+
+	for _, id := range importUses(f, old) {
+		id.Name = name
+	}
+	i.Name = &ast.Ident{NamePos: i.Path.Pos(), Name: name}
+	return nil
+}
+
+// importUses returns the identifiers of f referring to the import named name,
+// that is the package qualifiers of selector expressions that are not resolved
+// to a declaration of the file.
+func importUses(f *ast.File, name string) []*ast.Ident {
+	var uses []*ast.Ident
+	for _, sel := range importSelectors(f, name) {
+		uses = append(uses, sel.X.(*ast.Ident))
+	}
+	return uses
+}
+
+// importSelectors returns the selector expressions of f qualified by the
+// import named name.
+func importSelectors(f *ast.File, name string) []*ast.SelectorExpr {
+	var selectors []*ast.SelectorExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Name == name && id.Obj == nil {
+				selectors = append(selectors, sel)
+			}
+		}
+		return true
+	})
+	return selectors
+}
+
+// removeRedundantAliases drops the aliases of the imports of f that are the
+// same as the name of the imported package.
+func (r *resolver) removeRedundantAliases(f *ast.File, path string) {
+	for _, i := range f.Imports {
+		if i.Name == nil || i.Name.Name == "_" || i.Name.Name == "." {
+			continue
+		}
+		alias := i.Name
+		i.Name = nil
+		if r.importName(i) != alias.Name {
+			i.Name = alias
+			continue
+		}
+		klog.V(2).Infof("%s: removed redundant alias %s of import %s", path, alias.Name, i.Path.Value)
+	}
+}
@@ -0,0 +1,91 @@
+package imports
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssumedPackageName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{"fmt", "fmt"},
+		{"k8s.io/klog/v2", "klog"},
+		{"github.com/mitchellh/go-homedir", "homedir"},
+		{"gopkg.in/yaml.v2", "yaml"},
+		{"github.com/onsi/ginkgo/v2", "ginkgo"},
+		{"k8s.io/api/core/v1", "v1"},
+	}
+
+	for _, test := range tests {
+		if got := assumedPackageName(test.importPath); got != test.want {
+			t.Errorf("import: %s, wanted: %s, got %s", test.importPath, test.want, got)
+		}
+	}
+}
+
+func TestRemoveRedundantAliases(t *testing.T) {
+	moduleDir, err := os.MkdirTemp("", "tools-test")
+	if err != nil {
+		t.Fatalf("Failed to make temporary directory: %s", err)
+	}
+	defer os.RemoveAll(moduleDir)
+	packages := map[string]string{
+		"vendor/example.com/lib/renamed/renamed.go": "package different\n",
+		"vendor/example.com/lib/go-thing/thing.go":  "package thing\n",
+		"pkg/util/util.go":                          "package util\n",
+	}
+	for name, src := range packages {
+		path := filepath.Join(moduleDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to make directory: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+	}
+
+	src := `package main
+
+import (
+	fmt "fmt"
+	_ "embed"
+	renamed "example.com/lib/renamed"
+	thing "example.com/lib/go-thing"
+	klog "k8s.io/klog/v2"
+	utils "example.com/exampkg/pkg/util"
+	util "example.com/exampkg/pkg/util"
+)
+`
+	want := `package main
+
+import (
+	_ "embed"
+	"example.com/exampkg/pkg/util"
+	utils "example.com/exampkg/pkg/util"
+	"example.com/lib/go-thing"
+	renamed "example.com/lib/renamed"
+	"fmt"
+	"k8s.io/klog/v2"
+)
+`
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	newResolver(&Options{Module: "example.com/exampkg", ModuleDir: moduleDir}).removeRedundantAliases(f, "example.go")
+	var out bytes.Buffer
+	if err := format.Node(&out, fs, f); err != nil {
+		t.Fatalf("failed to print: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("wanted: %s, got %s", want, out.String())
+	}
+}
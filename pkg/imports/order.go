@@ -0,0 +1,169 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// SortLexical sorts the imports of a group by import path byte by byte,
+	// as gofmt does.
+	SortLexical = "lexical"
+	// SortNatural sorts the imports of a group with NaturalLess.
+	SortNatural = "natural"
+)
+
+// Sorts lists the supported sort strategies.
+var Sorts = []string{SortLexical, SortNatural}
+
+// Less tells whether the import path a goes before the import path b within
+// their group.
+type Less func(a, b string) bool
+
+// NaturalLess orders import paths element by element, comparing semantic
+// versions such as v2 and v10 by precedence, the runs of digits of other
+// elements by value, and letters regardless of case before falling back to
+// the byte order.
+func NaturalLess(a, b string) bool {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for n := 0; n < len(as) && n < len(bs); n++ {
+		if c := compareElements(as[n], bs[n]); c != 0 {
+			return c < 0
+		}
+	}
+	if len(as) != len(bs) {
+		return len(as) < len(bs)
+	}
+	return a < b
+}
+
+// compareElements compares the elements a and b of import paths for
+// NaturalLess.
+func compareElements(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if semver.IsValid(a) && semver.IsValid(b) {
+		if c := semver.Compare(a, b); c != 0 {
+			return c
+		}
+	}
+	x, y := a, b
+	for x != "" && y != "" {
+		var cx, cy string
+		cx, x = nextRun(x)
+		cy, y = nextRun(y)
+		var c int
+		if isDigit(cx[0]) && isDigit(cy[0]) {
+			c = compareNumbers(cx, cy)
+		} else {
+			c = strings.Compare(strings.ToLower(cx), strings.ToLower(cy))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	switch {
+	case x == "" && y != "":
+		return -1
+	case x != "" && y == "":
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// nextRun splits s after its leading run of digits, or of other characters.
+func nextRun(s string) (string, string) {
+	digits := isDigit(s[0])
+	n := 1
+	for n < len(s) && isDigit(s[n]) == digits {
+		n++
+	}
+	return s[:n], s[n:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// compareNumbers compares the runs of digits a and b by value, the one with
+// the fewest leading zeros first when they are equal.
+func compareNumbers(a, b string) int {
+	x, y := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(x) != len(y) {
+		return len(x) - len(y)
+	}
+	if c := strings.Compare(x, y); c != 0 {
+		return c
+	}
+	return len(a) - len(b)
+}
+
+// sortSpecs sorts specs by import path with less, or as gofmt does when less
+// is nil.
+func sortSpecs(specs []ast.ImportSpec, less Less) {
+	if less == nil {
+		sort.Sort(byPathValue(specs))
+		return
+	}
+	sort.SliceStable(specs, func(a, b int) bool {
+		return lessValue(specs[a].Path.Value, specs[b].Path.Value, less)
+	})
+}
+
+// lessValue tells whether the quoted import path a goes before b, comparing
+// them with less, or as gofmt does when less is nil.
+func lessValue(a, b string, less Less) bool {
+	if less == nil {
+		return a < b
+	}
+	x, errA := strconv.Unquote(a)
+	y, errB := strconv.Unquote(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return less(x, y)
+}
+
+// formatSource formats src as gofmt does, except that the imports are left in
+// their order when less is set, gofmt sorting them by import path.
+func formatSource(src []byte, less Less) ([]byte, error) {
+	if less == nil {
+		return format.Source(src)
+	}
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	printConfig := &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := printConfig.Fprint(&buf, fs, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
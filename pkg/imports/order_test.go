@@ -0,0 +1,103 @@
+package imports
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "example.com/mod/v2", b: "example.com/mod/v10", want: true},
+		{a: "example.com/mod/v10", b: "example.com/mod/v2", want: false},
+		{a: "k8s.io/api/core/v1", b: "k8s.io/api/core/v1beta1", want: true},
+		{a: "k8s.io/api/core/v1beta1", b: "k8s.io/api/core/v2", want: true},
+		{a: "k8s.io/api/apps/v1", b: "k8s.io/api/core/v1", want: true},
+		{a: "github.com/Azure/go-autorest", b: "github.com/aws/aws-sdk-go", want: false},
+		{a: "github.com/aws/aws-sdk-go", b: "github.com/Azure/go-autorest", want: true},
+		{a: "example.com/log2", b: "example.com/log10", want: true},
+		{a: "example.com/api", b: "example.com/api/v1", want: true},
+		{a: "example.com/api/v1", b: "example.com/api-v1", want: true},
+		{a: "example.com/Mod", b: "example.com/mod", want: true},
+		{a: "example.com/mod", b: "example.com/mod", want: false},
+	}
+	for _, test := range tests {
+		if got := NaturalLess(test.a, test.b); got != test.want {
+			t.Errorf("NaturalLess(%q, %q): wanted %t, got %t", test.a, test.b, test.want, got)
+		}
+	}
+}
+
+func TestFormatNaturalSort(t *testing.T) {
+	src := `package main
+
+import (
+	"github.com/example/mod/v10"
+	"github.com/aws/aws-sdk-go"
+	"github.com/example/mod/v2"
+	"github.com/Azure/go-autorest"
+)
+`
+	tests := []struct {
+		name string
+		sort Less
+		want string
+	}{
+		{
+			name: "lexical",
+			want: `package main
+
+import (
+	"github.com/Azure/go-autorest"
+	"github.com/aws/aws-sdk-go"
+	"github.com/example/mod/v10"
+	"github.com/example/mod/v2"
+)
+`,
+		},
+		{
+			name: "natural",
+			sort: NaturalLess,
+			want: `package main
+
+import (
+	"github.com/aws/aws-sdk-go"
+	"github.com/Azure/go-autorest"
+	"github.com/example/mod/v2"
+	"github.com/example/mod/v10"
+)
+`,
+		},
+	}
+	for _, test := range tests {
+		if got := formatFile(t, src, &Options{Sort: test.sort}); got != test.want {
+			t.Errorf("test: %s, wanted:\n%s\ngot:\n%s", test.name, test.want, got)
+		}
+	}
+}
+
+func TestUnsortedBlocksNaturalSort(t *testing.T) {
+	src := `package main
+
+import (
+	"github.com/example/mod/v2"
+	"github.com/example/mod/v10"
+)
+`
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := newRules("example.com/exampkg", nil)
+	if got := r.unsortedBlocks(fs, f, []byte(src), layout{less: NaturalLess}); len(got) != 0 {
+		t.Errorf("wanted no unsorted block, got %#v", got)
+	}
+	if got := r.unsortedBlocks(fs, f, []byte(src), layout{}); len(got) != 1 || !strings.Contains(got[0].message, "is not sorted") {
+		t.Errorf("wanted the block to be unsorted lexically, got %#v", got)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openshift-eng/openshift-goimports/pkg/util"
+)
+
+// Group is a group of imports.
+type Group struct {
+	// Name is the name of the group. A group named like an existing one adds
+	// its patterns to it.
+	Name string `mapstructure:"name"`
+	// Patterns are regular expressions matching the import paths of the
+	// group, like the intermediates.
+	Patterns []string `mapstructure:"patterns"`
+}
+
+// Override changes how the imports of some files are organized.
+type Override struct {
+	// Files are patterns of the files the override applies to, in the
+	// .gitignore syntax and relative to ModuleDir, such as *_test.go or
+	// test/e2e/**.
+	Files []string `mapstructure:"files"`
+	// Groups are added to the groups of the files. Their patterns are checked
+	// before the ones of the other groups, and they come after the module
+	// group unless Order says otherwise.
+	Groups []Group `mapstructure:"groups"`
+	// Order is the order of the groups of the files, by name or by pattern
+	// for intermediates. The groups left out come after the listed ones, in
+	// their usual order.
+	Order []string `mapstructure:"order"`
+	// BlankImports and DotImports replace the placements of the blank and
+	// dot imports of the files.
+	BlankImports string `mapstructure:"blankImports"`
+	DotImports   string `mapstructure:"dotImports"`
+}
+
+// fileRules are the rules organizing the imports of a file.
+type fileRules struct {
+	rules *rules
+	place placement
+}
+
+// overrides picks the rules of each file according to the overrides
+// applying to it.
+type overrides struct {
+	opts     *Options
+	base     string
+	files    [][]*util.Pattern
+	defaults fileRules
+	cache    map[string]fileRules
+}
+
+func newOverrides(opts *Options) (*overrides, error) {
+	base := opts.ModuleDir
+	if base == "" {
+		base = "."
+	}
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return nil, err
+	}
+	o := &overrides{opts: opts, base: base, cache: map[string]fileRules{}}
+	for _, override := range opts.Overrides {
+		for key, placement := range map[string]string{"blankImports": override.BlankImports, "dotImports": override.DotImports} {
+			if placement != "" && !IsPlacement(placement) {
+				return nil, fmt.Errorf("unknown placement %q of %s in override of %q, must be one of %s", placement, key, override.Files, strings.Join(Placements, ", "))
+			}
+		}
+		var patterns []*util.Pattern
+		for _, s := range override.Files {
+			p, err := util.ParsePattern(base, s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid override files: %v", err)
+			}
+			if p != nil {
+				patterns = append(patterns, p)
+			}
+		}
+		o.files = append(o.files, patterns)
+	}
+	// Build the rules of each override, along with the groups of the other
+	// ones since a file may match several, so that mistakes are found before
+	// any file is organized.
+	for n := range opts.Overrides {
+		var matching []int
+		for other := range opts.Overrides {
+			if other != n {
+				matching = append(matching, other)
+			}
+		}
+		if _, err := o.build(append(matching, n)); err != nil {
+			return nil, err
+		}
+	}
+	if o.defaults, err = o.build(nil); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// forFile returns the rules of the file at path.
+func (o *overrides) forFile(path string) (fileRules, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fileRules{}, err
+	}
+	var matching []int
+	var key []string
+	for n, patterns := range o.files {
+		for _, p := range patterns {
+			if util.Ignored([]*util.Pattern{p}, abs, false) {
+				matching = append(matching, n)
+				key = append(key, strconv.Itoa(n))
+				break
+			}
+		}
+	}
+	if len(matching) == 0 {
+		return o.defaults, nil
+	}
+	if rules, ok := o.cache[strings.Join(key, ",")]; ok {
+		return rules, nil
+	}
+	rules, err := o.build(matching)
+	if err != nil {
+		return fileRules{}, err
+	}
+	o.cache[strings.Join(key, ",")] = rules
+	return rules, nil
+}
+
+// build returns the rules resulting from applying the overrides numbered
+// matching, in order, to the options.
+func (o *overrides) build(matching []int) (fileRules, error) {
+	r, err := newRules(o.opts.Module, o.opts.Intermediates)
+	if err != nil {
+		return fileRules{}, err
+	}
+	placements := *o.opts
+	var order []string
+	for _, n := range matching {
+		override := o.opts.Overrides[n]
+		for _, group := range override.Groups {
+			if err := r.addGroup(group); err != nil {
+				return fileRules{}, err
+			}
+		}
+		if override.BlankImports != "" {
+			placements.BlankImports = override.BlankImports
+		}
+		if override.DotImports != "" {
+			placements.DotImports = override.DotImports
+		}
+		if len(override.Order) > 0 {
+			order = override.Order
+		}
+	}
+	place := newPlacement(r, &placements)
+	if err := r.reorder(order); err != nil {
+		return fileRules{}, err
+	}
+	return fileRules{rules: r, place: place}, nil
+}
+
+// addGroup checks the patterns of group before the ones of the groups added
+// by newRules, and adds the group after the other ones unless it exists.
+func (r *rules) addGroup(group Group) error {
+	if group.Name == "" {
+		return fmt.Errorf("group with patterns %q has no name", group.Patterns)
+	}
+	if len(group.Patterns) == 0 {
+		return fmt.Errorf("group %q has no patterns", group.Name)
+	}
+	var regexps []ImportRegexp
+	for _, pattern := range group.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern of group %q: %v", group.Name, err)
+		}
+		regexps = append(regexps, ImportRegexp{Bucket: group.Name, Regexp: re})
+	}
+	r.importRegexp = append(r.importRegexp[:r.custom], append(regexps, r.importRegexp[r.custom:]...)...)
+	r.custom += len(regexps)
+	if r.order(group.Name) == len(r.importOrder) {
+		r.importOrder = append(r.importOrder, group.Name)
+	}
+	return nil
+}
+
+// reorder puts the groups named in order, or the intermediate groups with
+// these patterns, first.
+func (r *rules) reorder(order []string) error {
+	var reordered []string
+	listed := map[string]bool{}
+	for _, name := range order {
+		bucket := name
+		for b, pattern := range r.intermediate {
+			if pattern == name {
+				bucket = b
+			}
+		}
+		if r.order(bucket) == len(r.importOrder) {
+			return fmt.Errorf("unknown group %q in order", name)
+		}
+		reordered = append(reordered, bucket)
+		listed[bucket] = true
+	}
+	for _, bucket := range r.importOrder {
+		if !listed[bucket] {
+			reordered = append(reordered, bucket)
+		}
+	}
+	r.importOrder = reordered
+	return nil
+}
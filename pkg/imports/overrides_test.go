@@ -0,0 +1,144 @@
+package imports
+
+import (
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	src := `package e2e
+
+import (
+	"testing"
+	. "github.com/onsi/gomega"
+	"github.com/example/module/test/util"
+	"github.com/example/module/pkg/api"
+	"github.com/spf13/cobra"
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/api/core/v1"
+)
+`
+	overrides := []Override{
+		{
+			Files:  []string{"*_test.go", "test/e2e/**"},
+			Groups: []Group{{Name: "testing", Patterns: []string{"github.com/onsi/", "github.com/example/module/test/"}}},
+		},
+		{
+			Files: []string{"test/e2e/**"},
+			Order: []string{"testing", "standard"},
+		},
+	}
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{
+			name: "no override",
+			file: "pkg/api/types.go",
+			want: `package e2e
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/example/module/pkg/api"
+	"github.com/example/module/test/util"
+)
+`,
+		},
+		{
+			name: "added group",
+			file: "pkg/api/types_test.go",
+			want: `package e2e
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/example/module/pkg/api"
+
+	"github.com/example/module/test/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+`,
+		},
+		{
+			name: "reordered groups",
+			file: "test/e2e/e2e.go",
+			want: `package e2e
+
+import (
+	"github.com/example/module/test/util"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/example/module/pkg/api"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatNamedFile(t, tt.file, src, &Options{Overrides: overrides}); got != tt.want {
+			t.Errorf("%s: wanted:\n%s\ngot:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOverrideExtendsGroup(t *testing.T) {
+	src := `package main
+
+import (
+	"sigs.k8s.io/yaml"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+)
+`
+	want := `package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+`
+	overrides := []Override{{Files: []string{"*.go"}, Groups: []Group{{Name: "kubernetes", Patterns: []string{"sigs.k8s.io/"}}}}}
+	if got := formatFile(t, src, &Options{Overrides: overrides}); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestNewOverridesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []Override
+	}{
+		{name: "unnamed group", overrides: []Override{{Files: []string{"*_test.go"}, Groups: []Group{{Patterns: []string{"onsi"}}}}}},
+		{name: "group without patterns", overrides: []Override{{Files: []string{"*_test.go"}, Groups: []Group{{Name: "testing"}}}}},
+		{name: "invalid pattern", overrides: []Override{{Files: []string{"*_test.go"}, Groups: []Group{{Name: "testing", Patterns: []string{"("}}}}}},
+		{name: "unknown group", overrides: []Override{{Files: []string{"*_test.go"}, Order: []string{"testing"}}}},
+		{name: "invalid files", overrides: []Override{{Files: []string{"[a"}}}},
+		{name: "unknown blank placement", overrides: []Override{{Files: []string{"*_test.go"}, BlankImports: "top"}}},
+		{name: "unknown dot placement", overrides: []Override{{Files: []string{"*_test.go"}, DotImports: "bottom"}}},
+	}
+	for _, tt := range tests {
+		if _, err := newOverrides(&Options{Module: "github.com/example/module", Overrides: tt.overrides}); err == nil {
+			t.Errorf("%s: wanted an error", tt.name)
+		}
+	}
+}
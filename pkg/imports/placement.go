@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"go/ast"
+)
+
+const (
+	// PlacementSorted sorts imports among the other imports of their group.
+	PlacementSorted = "sorted"
+	// PlacementFirst puts imports in a sub-group before the other imports of
+	// their group.
+	PlacementFirst = "first"
+	// PlacementLast puts imports in a sub-group after the other imports of
+	// their group.
+	PlacementLast = "last"
+	// PlacementGroup puts imports in a group of their own, after the other
+	// groups.
+	PlacementGroup = "group"
+
+	// BlankGroup is the group of the blank imports placed in their own group.
+	BlankGroup = "blank"
+	// DotGroup is the group of the dot imports placed in their own group.
+	DotGroup = "dot"
+)
+
+// Placements lists the supported placements of blank and dot imports.
+var Placements = []string{PlacementSorted, PlacementFirst, PlacementLast, PlacementGroup}
+
+// IsPlacement returns whether p is one of Placements.
+func IsPlacement(p string) bool {
+	for _, placement := range Placements {
+		if p == placement {
+			return true
+		}
+	}
+	return false
+}
+
+// placement tells where the blank and dot imports go.
+type placement struct {
+	blank string
+	dot   string
+}
+
+// newPlacement returns the placement configured by opts, adding the groups
+// of blank and dot imports to r when they have their own.
+func newPlacement(r *rules, opts *Options) placement {
+	p := placement{blank: opts.BlankImports, dot: opts.DotImports}
+	if p.blank == PlacementGroup {
+		r.importOrder = append(r.importOrder, BlankGroup)
+	}
+	if p.dot == PlacementGroup {
+		r.importOrder = append(r.importOrder, DotGroup)
+	}
+	return p
+}
+
+// of returns the placement of i.
+func (p placement) of(i *ast.ImportSpec) string {
+	switch {
+	case i.Name != nil && i.Name.Name == "_" && p.blank != "":
+		return p.blank
+	case i.Name != nil && i.Name.Name == "." && p.dot != "":
+		return p.dot
+	}
+	return PlacementSorted
+}
+
+// bucket returns the group of i, its own group when blank or dot imports
+// have one.
+func (p placement) bucket(r *rules, i *ast.ImportSpec) string {
+	if p.of(i) == PlacementGroup {
+		if i.Name.Name == "_" {
+			return BlankGroup
+		}
+		return DotGroup
+	}
+	return r.classify(i.Path.Value)
+}
+
+// rank returns the position of the sub-group of i among the ones of its
+// group.
+func (p placement) rank(i *ast.ImportSpec) int {
+	switch p.of(i) {
+	case PlacementFirst:
+		return -1
+	case PlacementLast:
+		return 1
+	}
+	return 0
+}
+
+// active returns whether p changes the order of the imports of a group.
+func (p placement) active() bool {
+	return p.blank == PlacementFirst || p.blank == PlacementLast || p.dot == PlacementFirst || p.dot == PlacementLast
+}
@@ -0,0 +1,90 @@
+package imports
+
+import (
+	"testing"
+)
+
+func TestPlacement(t *testing.T) {
+	src := `package e2e
+
+import (
+	. "github.com/onsi/gomega"
+	"os"
+	_ "embed"
+	"github.com/spf13/cobra"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/client-go/kubernetes"
+)
+`
+	tests := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{
+			name: "sorted",
+			opts: &Options{},
+			want: `package e2e
+
+import (
+	_ "embed"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+`,
+		},
+		{
+			name: "own groups",
+			opts: &Options{BlankImports: PlacementGroup, DotImports: PlacementGroup},
+			want: `package e2e
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/kubernetes"
+
+	_ "embed"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+`,
+		},
+		{
+			name: "first and last",
+			opts: &Options{BlankImports: PlacementLast, DotImports: PlacementFirst},
+			want: `package e2e
+
+import (
+	"os"
+
+	_ "embed"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/kubernetes"
+
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatFile(t, src, tt.opts); got != tt.want {
+			t.Errorf("%s: wanted:\n%s\ngot:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"sync"
+)
+
+// Progress counts the files processed and skipped by Format, and records the
+// ones left out when it is cancelled. It is safe for concurrent use.
+type Progress struct {
+	mu        sync.Mutex
+	processed int
+	skipped   int
+	left      []string
+}
+
+// Processed returns the number of files processed so far, not counting the
+// skipped ones nor the one being processed.
+func (p *Progress) Processed() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.processed
+}
+
+// Skipped returns the number of files skipped so far, such as the generated
+// and ignored ones.
+func (p *Progress) Skipped() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.skipped
+}
+
+// Left returns the files received but left out since Format was cancelled.
+func (p *Progress) Left() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string{}, p.left...)
+}
+
+func (p *Progress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed++
+}
+
+func (p *Progress) skip() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.skipped++
+}
+
+func (p *Progress) leave(path string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.left = append(p.left, path)
+}
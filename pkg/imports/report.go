@@ -0,0 +1,194 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// OutputText reports problems as plain text, one per line.
+	OutputText = "text"
+	// OutputGitHub reports problems as GitHub Actions workflow commands so
+	// they are shown inline in pull request diffs.
+	OutputGitHub = "github"
+)
+
+// Outputs lists the supported output formats.
+var Outputs = []string{OutputText, OutputGitHub}
+
+// IsOutput returns whether output is one of Outputs.
+func IsOutput(output string) bool {
+	for _, o := range Outputs {
+		if o == output {
+			return true
+		}
+	}
+	return false
+}
+
+// Reporter writes the problems found in files. It is safe for concurrent use.
+type Reporter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	output string
+	count  int
+}
+
+// NewReporter returns a Reporter writing problems to out in the given output format.
+func NewReporter(out io.Writer, output string) *Reporter {
+	return &Reporter{out: out, output: output}
+}
+
+// Report records a problem found at line of file.
+func (r *Reporter) Report(file string, line int, message string) {
+	if r == nil {
+		klog.Warningf("%s:%d: %s", file, line, message)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	switch r.output {
+	case OutputGitHub:
+		fmt.Fprintf(r.out, "::error file=%s,line=%d::%s\n", escapeProperty(file), line, escapeData(message))
+	default:
+		fmt.Fprintf(r.out, "%s:%d: %s\n", file, line, message)
+	}
+}
+
+// Count returns the number of problems reported so far.
+func (r *Reporter) Count() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// escapeData escapes the message of a workflow command, see
+// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// unsortedBlock is an import declaration whose specs are not organized.
+type unsortedBlock struct {
+	line    int
+	message string
+}
+
+// layout is how the imports of a group are laid out beyond being sorted.
+type layout struct {
+	// frozen are the imports left as they are.
+	frozen map[*ast.ImportSpec]bool
+	// subgroups allows the imports of a group to be separated by blank lines.
+	subgroups bool
+	// split tells the sub-groups the imports of a group are split into.
+	split *splitter
+	// place tells where the blank and dot imports go.
+	place placement
+	// less orders the imports of a sub-group, as gofmt does when nil.
+	less Less
+}
+
+// unsortedBlocks returns the import declarations of f whose specs are not
+// ordered and separated according to r and l, naming the group expected for
+// the first misplaced import of each. The frozen imports are left out.
+func (r *rules) unsortedBlocks(fs *token.FileSet, f *ast.File, contents []byte, l layout) []unsortedBlock {
+	lines := bytes.Split(contents, []byte("\n"))
+	var unsorted []unsortedBlock
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var prev *ast.ImportSpec
+		for _, spec := range gen.Specs {
+			i := spec.(*ast.ImportSpec)
+			if l.frozen[i] {
+				continue
+			}
+			if prev == nil {
+				prev = i
+				continue
+			}
+			bucket, prevBucket := l.place.bucket(r, i), l.place.bucket(r, prev)
+			sub := subgroup{rank: l.place.rank(i), split: l.split.key(r, bucket, i.Path.Value)}
+			prevSub := subgroup{rank: l.place.rank(prev), split: l.split.key(r, prevBucket, prev.Path.Value)}
+			blank := blankLineBetween(lines, fs.Position(prev.End()).Line, fs.Position(i.Pos()).Line)
+			var message string
+			switch {
+			case r.order(bucket) < r.order(prevBucket):
+				message = fmt.Sprintf("import %s belongs in the %s group, before the %s group", i.Path.Value, r.describe(bucket), r.describe(prevBucket))
+			case bucket == prevBucket && sub.split < prevSub.split && sub.rank == prevSub.rank && !(l.subgroups && blank):
+				message = fmt.Sprintf("import %s belongs in the %s sub-group of the %s group, before the %s sub-group", i.Path.Value, sub.split, r.describe(bucket), prevSub.split)
+			case bucket == prevBucket && (sub.rank < prevSub.rank || sub == prevSub && lessValue(i.Path.Value, prev.Path.Value, l.less)) && !(l.subgroups && blank):
+				message = fmt.Sprintf("import %s is not sorted within the %s group", i.Path.Value, r.describe(bucket))
+			case bucket != prevBucket && !blank:
+				message = fmt.Sprintf("import %s starts the %s group and must be preceded by a blank line", i.Path.Value, r.describe(bucket))
+			case bucket == prevBucket && sub.split != prevSub.split && !blank:
+				message = fmt.Sprintf("import %s starts the %s sub-group of the %s group and must be preceded by a blank line", i.Path.Value, sub.split, r.describe(bucket))
+			case bucket == prevBucket && sub != prevSub && !blank:
+				message = fmt.Sprintf("import %s must be separated from the other imports of the %s group by a blank line", i.Path.Value, r.describe(bucket))
+			case bucket == prevBucket && sub == prevSub && blank && !l.subgroups:
+				message = fmt.Sprintf("import %s belongs in the %s group with the imports before it", i.Path.Value, r.describe(bucket))
+			}
+			if message != "" {
+				unsorted = append(unsorted, unsortedBlock{line: fs.Position(gen.Pos()).Line, message: message})
+				break
+			}
+			prev = i
+		}
+	}
+	return unsorted
+}
+
+// blankLineBetween returns whether one of the lines strictly between the 1-based
+// lines from and to is empty.
+func blankLineBetween(lines [][]byte, from, to int) bool {
+	for n := from + 1; n < to && n <= len(lines); n++ {
+		if len(bytes.TrimSpace(lines[n-1])) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// firstImportLine returns the line of the first import declaration of f, or the
+// line of the package clause when f has no imports.
+func firstImportLine(fs *token.FileSet, f *ast.File) int {
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			return fs.Position(gen.Pos()).Line
+		}
+	}
+	return fs.Position(f.Package).Line
+}
@@ -0,0 +1,191 @@
+package imports
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestUnsortedBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		subgroups bool
+		split     *splitter
+		place     placement
+		want      []unsortedBlock
+	}{
+		{
+			name: "sorted",
+			src: `package main
+
+import (
+	"os"
+
+	"k8s.io/klog/v2"
+)
+`,
+		},
+		{
+			name: "wrong group order",
+			src: `package main
+
+import (
+	"k8s.io/klog/v2"
+	"os"
+)
+`,
+			want: []unsortedBlock{{line: 3, message: `import "os" belongs in the standard group, before the kubernetes group`}},
+		},
+		{
+			name: "not sorted within group",
+			src: `package main
+
+import (
+	"os"
+	"fmt"
+)
+`,
+			want: []unsortedBlock{{line: 3, message: `import "fmt" is not sorted within the standard group`}},
+		},
+		{
+			name: "missing blank line",
+			src: `package main
+
+import (
+	"os"
+	"thirdy.io/two"
+)
+`,
+			want: []unsortedBlock{{line: 3, message: `import "thirdy.io/two" starts the intermediate "thirdy.io/two" group and must be preceded by a blank line`}},
+		},
+		{
+			name: "extra blank line",
+			src: `package main
+
+import "fmt"
+
+import (
+	"os"
+	// comment
+
+	"path"
+)
+`,
+			want: []unsortedBlock{{line: 5, message: `import "path" belongs in the standard group with the imports before it`}},
+		},
+		{
+			name: "sub-groups",
+			src: `package main
+
+import (
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/apps/v1"
+)
+`,
+			subgroups: true,
+			want:      []unsortedBlock{{line: 3, message: `import "k8s.io/api/apps/v1" is not sorted within the kubernetes group`}},
+		},
+		{
+			name: "split sub-groups",
+			src: `package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/onsi/gomega"
+)
+`,
+			split: newSplitter(&Options{SplitBy: SplitByOrganization}),
+			want:  []unsortedBlock{{line: 3, message: `import "github.com/onsi/gomega" belongs in the github.com/onsi sub-group of the other group, before the github.com/spf13 sub-group`}},
+		},
+		{
+			name: "split sub-groups without blank line",
+			src: `package main
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+`,
+			split: newSplitter(&Options{SplitBy: SplitByOrganization}),
+			want:  []unsortedBlock{{line: 3, message: `import "github.com/spf13/cobra" starts the github.com/spf13 sub-group of the other group and must be preceded by a blank line`}},
+		},
+		{
+			name: "dot imports first",
+			src: `package main
+
+import (
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/gomega"
+)
+`,
+			place: placement{dot: PlacementFirst},
+			want:  []unsortedBlock{{line: 3, message: `import "github.com/onsi/gomega" is not sorted within the other group`}},
+		},
+		{
+			name: "blank imports last without blank line",
+			src: `package main
+
+import (
+	_ "embed"
+	"os"
+)
+`,
+			place: placement{blank: PlacementLast},
+			want:  []unsortedBlock{{line: 3, message: `import "os" is not sorted within the standard group`}},
+		},
+	}
+
+	r, _ := newRules("example.com/exampkg", []string{"thirdy.io/two"})
+	for _, test := range tests {
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, "example.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("test: %s, failed to parse: %v", test.name, err)
+		}
+		got := r.unsortedBlocks(fs, f, []byte(test.src), layout{subgroups: test.subgroups, split: test.split, place: test.place})
+		if len(got) != len(test.want) {
+			t.Fatalf("test: %s, wanted: %#v, got %#v", test.name, test.want, got)
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("test: %s, wanted: %#v, got %#v", test.name, test.want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestReporter(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{
+			output: OutputText,
+			want:   "pkg/a,b.go:3: import \"os\" belongs in 100% the\nstandard group\n",
+		},
+		{
+			output: OutputGitHub,
+			want:   "::error file=pkg/a%2Cb.go,line=3::import \"os\" belongs in 100%25 the%0Astandard group\n",
+		},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		r := NewReporter(&out, test.output)
+		r.Report("pkg/a,b.go", 3, "import \"os\" belongs in 100% the\nstandard group")
+		if out.String() != test.want {
+			t.Errorf("output: %s, wanted: %q, got %q", test.output, test.want, out.String())
+		}
+		if r.Count() != 1 {
+			t.Errorf("output: %s, wanted 1 problem, got %d", test.output, r.Count())
+		}
+	}
+}
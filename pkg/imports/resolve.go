@@ -0,0 +1,164 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// resolver finds the source of imported packages on disk, without using the
+// network, looking at the module being organized, its vendor directory, the
+// standard library and the module cache. It is safe for concurrent use.
+type resolver struct {
+	module       string
+	moduleDir    string
+	requirements []module.Version
+	goroot       string
+	modCache     string
+
+	mu           sync.Mutex
+	names        map[string]string
+	declarations map[string]declarations
+}
+
+func newResolver(opts *Options) *resolver {
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if gopath := filepath.SplitList(build.Default.GOPATH); len(gopath) > 0 {
+			modCache = filepath.Join(gopath[0], "pkg", "mod")
+		}
+	}
+	return &resolver{
+		module:       opts.Module,
+		moduleDir:    opts.ModuleDir,
+		requirements: opts.Requirements,
+		goroot:       build.Default.GOROOT,
+		modCache:     modCache,
+		names:        map[string]string{},
+		declarations: map[string]declarations{},
+	}
+}
+
+// dir returns the directory holding the source of the package importPath.
+func (r *resolver) dir(importPath string) (string, bool) {
+	var candidates []string
+	if r.moduleDir != "" {
+		if hasPathPrefix(importPath, r.module) {
+			candidates = append(candidates, filepath.Join(r.moduleDir, filepath.FromSlash(strings.TrimPrefix(importPath, r.module))))
+		}
+		candidates = append(candidates, filepath.Join(r.moduleDir, "vendor", filepath.FromSlash(importPath)))
+	}
+	if r.goroot != "" && !strings.Contains(strings.Split(importPath, "/")[0], ".") {
+		candidates = append(candidates, filepath.Join(r.goroot, "src", filepath.FromSlash(importPath)))
+	}
+	if r.modCache != "" {
+		if m := moduleOf(importPath, "", r.requirements); m != "" {
+			for _, req := range r.requirements {
+				if req.Path != m {
+					continue
+				}
+				escapedPath, err := module.EscapePath(req.Path)
+				if err != nil {
+					break
+				}
+				escapedVersion, err := module.EscapeVersion(req.Version)
+				if err != nil {
+					break
+				}
+				candidates = append(candidates, filepath.Join(r.modCache, filepath.FromSlash(escapedPath)+"@"+escapedVersion, filepath.FromSlash(strings.TrimPrefix(importPath, req.Path))))
+			}
+		}
+	}
+	for _, dir := range candidates {
+		if s, err := os.Stat(dir); err == nil && s.IsDir() {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// packageName returns the name declared by the source of the package
+// importPath, if it can be found.
+func (r *resolver) packageName(importPath string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.Lock()
+	name, ok := r.names[importPath]
+	r.mu.Unlock()
+	if ok {
+		return name, name != ""
+	}
+
+	if dir, ok := r.dir(importPath); ok {
+		name = readPackageName(dir)
+	}
+	r.mu.Lock()
+	r.names[importPath] = name
+	r.mu.Unlock()
+	return name, name != ""
+}
+
+// importName returns the name a file refers to the import i by, using the
+// actual package name when its source can be found.
+func (r *resolver) importName(i *ast.ImportSpec) string {
+	if i.Name != nil {
+		return i.Name.Name
+	}
+	importPath, err := strconv.Unquote(i.Path.Value)
+	if err != nil {
+		return ""
+	}
+	if name, ok := r.packageName(importPath); ok {
+		return name
+	}
+	return assumedPackageName(importPath)
+}
+
+// readPackageName returns the package name most declared by the non test go
+// files of dir.
+func readPackageName(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	counts := map[string]int{}
+	best := ""
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, e.Name()), nil, parser.PackageClauseOnly)
+		if err != nil || f.Name.Name == "documentation" {
+			continue
+		}
+		counts[f.Name.Name]++
+		if counts[f.Name.Name] > counts[best] || (counts[f.Name.Name] == counts[best] && f.Name.Name < best) {
+			best = f.Name.Name
+		}
+	}
+	return best
+}
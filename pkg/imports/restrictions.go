@@ -0,0 +1,170 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RestrictionsFile is the name of the files holding the import restrictions
+// of a directory and the directories under it, in the format of the
+// Kubernetes import-boss tool.
+const RestrictionsFile = ".import-restrictions"
+
+// RestrictionRule restricts the imports matching SelectorRegexp to the
+// AllowedPrefixes, unless they match one of the ForbiddenPrefixes.
+type RestrictionRule struct {
+	SelectorRegexp    string   `json:"selectorRegexp" yaml:"selectorRegexp"`
+	AllowedPrefixes   []string `json:"allowedPrefixes" yaml:"allowedPrefixes"`
+	ForbiddenPrefixes []string `json:"forbiddenPrefixes" yaml:"forbiddenPrefixes"`
+
+	selector *regexp.Regexp
+}
+
+// Restrictions are the rules of an import restrictions file.
+type Restrictions struct {
+	Rules []RestrictionRule `json:"rules" yaml:"rules"`
+
+	path string
+}
+
+// readRestrictions reads the import restrictions file at path, which is
+// either YAML or, as written for older versions of import-boss, JSON.
+func readRestrictions(path string) (*Restrictions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Restrictions{path: path}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, r)
+	} else {
+		err = yaml.Unmarshal(data, r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	for n := range r.Rules {
+		if r.Rules[n].selector, err = regexp.Compile(r.Rules[n].SelectorRegexp); err != nil {
+			return nil, fmt.Errorf("invalid selectorRegexp in %s: %v", path, err)
+		}
+	}
+	return r, nil
+}
+
+// check returns whether importPath is forbidden or explicitly allowed by the
+// first rule whose selector matches it.
+func (r *Restrictions) check(importPath string) (forbidden, allowed bool) {
+	for _, rule := range r.Rules {
+		if !rule.selector.MatchString(importPath) {
+			continue
+		}
+		for _, prefix := range rule.ForbiddenPrefixes {
+			if strings.HasPrefix(importPath, prefix) {
+				return true, false
+			}
+		}
+		for _, prefix := range rule.AllowedPrefixes {
+			if strings.HasPrefix(importPath, prefix) {
+				return false, true
+			}
+		}
+		return true, false
+	}
+	return false, false
+}
+
+// restrictions finds and caches the import restrictions files applying to
+// directories.
+type restrictions struct {
+	root string
+	dirs map[string][]*Restrictions
+}
+
+func newRestrictions(root string) *restrictions {
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	return &restrictions{root: root, dirs: map[string][]*Restrictions{}}
+}
+
+// forDir returns the restrictions applying to dir, from the closest file
+// up to the root directory.
+func (r *restrictions) forDir(dir string) ([]*Restrictions, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if found, ok := r.dirs[dir]; ok {
+		return found, nil
+	}
+
+	var found []*Restrictions
+	if parent := filepath.Dir(dir); parent != dir && dir != r.root {
+		if found, err = r.forDir(parent); err != nil {
+			return nil, err
+		}
+	}
+	path := filepath.Join(dir, RestrictionsFile)
+	if _, err := os.Stat(path); err == nil {
+		restrictions, err := readRestrictions(path)
+		if err != nil {
+			return nil, err
+		}
+		found = append([]*Restrictions{restrictions}, found...)
+	}
+	r.dirs[dir] = found
+	return found, nil
+}
+
+// checkRestrictions reports the imports of f forbidden by the import
+// restrictions files of its directory or the directories above it. The
+// closest file deciding on an import wins.
+func (r *restrictions) checkRestrictions(fs *token.FileSet, f *ast.File, path string, reporter *Reporter) error {
+	found, err := r.forDir(filepath.Dir(path))
+	if err != nil || len(found) == 0 {
+		return err
+	}
+	for _, i := range f.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		for _, restrictions := range found {
+			forbidden, allowed := restrictions.check(importPath)
+			if forbidden {
+				reporter.Report(path, fs.Position(i.Pos()).Line, fmt.Sprintf("import %s is forbidden by %s", i.Path.Value, restrictions.path))
+			}
+			if forbidden || allowed {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckRestrictions(t *testing.T) {
+	moduleDir, err := os.MkdirTemp("", "tools-test")
+	if err != nil {
+		t.Fatalf("Failed to make temporary directory: %s", err)
+	}
+	defer os.RemoveAll(moduleDir)
+
+	files := map[string]string{
+		// Forbid the whole module from importing the controllers, except for
+		// the cmd directory that allows them explicitly.
+		RestrictionsFile: `rules:
+- selectorRegexp: example[.]com/exampkg/pkg/controller
+  forbiddenPrefixes:
+  - example.com/exampkg/pkg/controller
+inverseRules: []
+`,
+		filepath.Join("cmd", RestrictionsFile): `{
+  "Rules": [
+    {
+      "SelectorRegexp": "example[.]com",
+      "AllowedPrefixes": ["example.com/exampkg/pkg/controller"]
+    }
+  ]
+}
+`,
+		filepath.Join("pkg", "apis", RestrictionsFile): `rules:
+- selectorRegexp: k8s[.]io
+  allowedPrefixes:
+  - k8s.io/apimachinery
+`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(moduleDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to make directory: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+	}
+
+	src := `package main
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"example.com/exampkg/pkg/controller"
+)
+`
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{
+			dir: filepath.Join("pkg", "apis", "v1"),
+			want: fmt.Sprintf(`%[1]s:6: import "k8s.io/api/core/v1" is forbidden by %[2]s
+%[1]s:9: import "example.com/exampkg/pkg/controller" is forbidden by %[3]s
+`, filepath.Join(moduleDir, "pkg", "apis", "v1", "example.go"), filepath.Join(moduleDir, "pkg", "apis", RestrictionsFile), filepath.Join(moduleDir, RestrictionsFile)),
+		},
+		{
+			dir:  "cmd",
+			want: "",
+		},
+		{
+			dir: "pkg",
+			want: fmt.Sprintf("%s:9: import \"example.com/exampkg/pkg/controller\" is forbidden by %s\n",
+				filepath.Join(moduleDir, "pkg", "example.go"), filepath.Join(moduleDir, RestrictionsFile)),
+		},
+	}
+
+	r := newRestrictions(moduleDir)
+	for _, test := range tests {
+		path := filepath.Join(moduleDir, test.dir, "example.go")
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, path, src, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("failed to parse: %v", err)
+		}
+		var report bytes.Buffer
+		if err := r.checkRestrictions(fs, f, path, NewReporter(&report, OutputText)); err != nil {
+			t.Fatalf("dir: %s, unexpected error: %v", test.dir, err)
+		}
+		if report.String() != test.want {
+			t.Errorf("dir: %s, wanted: %q, got %q", test.dir, test.want, report.String())
+		}
+	}
+}
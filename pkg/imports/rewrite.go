@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// Rewrite moves the imports of Old, and of the packages under it, to New.
+type Rewrite struct {
+	Old string
+	New string
+}
+
+// ParseRewrite parses a rewrite written as old=new.
+func ParseRewrite(s string) (Rewrite, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Rewrite{}, fmt.Errorf("invalid rewrite %q, must be old=new", s)
+	}
+	return Rewrite{Old: strings.TrimSuffix(parts[0], "/"), New: strings.TrimSuffix(parts[1], "/")}, nil
+}
+
+// rewrite returns the import path importPath is moved to by the rewrite with
+// the longest matching Old, if any.
+func rewrite(importPath string, rewrites []Rewrite) (string, bool) {
+	best := -1
+	for n, r := range rewrites {
+		if hasPathPrefix(importPath, r.Old) && (best < 0 || len(r.Old) > len(rewrites[best].Old)) {
+			best = n
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	return rewrites[best].New + strings.TrimPrefix(importPath, rewrites[best].Old), true
+}
+
+// rewriteImports moves the imports of f according to rewrites, returning
+// whether any was. An import whose package name changes keeps its old name
+// as an alias, so the rest of the file still refers to it.
+func (r *resolver) rewriteImports(f *ast.File, path string, rewrites []Rewrite) bool {
+	rewritten := false
+	for _, i := range f.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		newPath, ok := rewrite(importPath, rewrites)
+		if !ok || newPath == importPath {
+			continue
+		}
+		oldName := r.importName(i)
+		i.Path.Value = strconv.Quote(newPath)
+		if i.Name == nil && r.importName(i) != oldName {
+			i.Name = &ast.Ident{NamePos: i.Path.Pos(), Name: oldName}
+		}
+		klog.V(2).Infof("%s: rewrote import %q to %q", path, importPath, newPath)
+		rewritten = true
+	}
+	return rewritten
+}
+
+// rewriteGenerated moves the imports of the generated file f according to the
+// rewrites of fm without organizing them, so the generated code keeps
+// building. It returns whether any import was moved.
+func (fm *formatter) rewriteGenerated(fs *token.FileSet, f *ast.File, path string, contents []byte) bool {
+	if !fm.resolver.rewriteImports(f, path, fm.opts.Rewrites) {
+		return false
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fs, f); err != nil {
+		fm.opts.Reporter.Report(path, firstImportLine(fs, f), fmt.Sprintf("internal error, leaving the file as is: %v", err))
+		return true
+	}
+	fm.update(fs, f, path, contents, buf.Bytes(), nil)
+	return true
+}
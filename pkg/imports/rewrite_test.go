@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"bytes"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestParseRewrite(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rewrite
+		wantErr bool
+	}{
+		{in: "k8s.io/utils/pointer=k8s.io/utils/ptr", want: Rewrite{Old: "k8s.io/utils/pointer", New: "k8s.io/utils/ptr"}},
+		{in: "github.com/a/=github.com/b/", want: Rewrite{Old: "github.com/a", New: "github.com/b"}},
+		{in: "github.com/a", wantErr: true},
+		{in: "=github.com/b", wantErr: true},
+		{in: "github.com/a=", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRewrite(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: wanted error %v, got %v", tt.in, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: wanted %v, got %v", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	rewrites := []Rewrite{
+		{Old: "github.com/openshift/library-go", New: "github.com/example/library"},
+		{Old: "github.com/openshift/library-go/pkg/operator", New: "github.com/example/operator"},
+	}
+	tests := []struct {
+		importPath string
+		want       string
+		wantOK     bool
+	}{
+		{importPath: "github.com/openshift/library-go/pkg/crypto", want: "github.com/example/library/pkg/crypto", wantOK: true},
+		{importPath: "github.com/openshift/library-go/pkg/operator/events", want: "github.com/example/operator/events", wantOK: true},
+		{importPath: "github.com/openshift/library-go", want: "github.com/example/library", wantOK: true},
+		{importPath: "github.com/openshift/library-golang", wantOK: false},
+		{importPath: "github.com/openshift/api", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := rewrite(tt.importPath, rewrites)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: wanted %q %v, got %q %v", tt.importPath, tt.want, tt.wantOK, got, ok)
+		}
+	}
+}
+
+func TestRewriteImports(t *testing.T) {
+	src := `package main
+
+import (
+	"k8s.io/utils/pointer"
+	utilnet "k8s.io/utils/net"
+	"github.com/openshift/api/config/v1"
+)
+
+var _ = pointer.Int32(1)
+`
+	want := `package main
+
+import (
+	"github.com/openshift/api/config/v1"
+	utilnet "k8s.io/utils/network"
+	pointer "k8s.io/utils/ptr"
+)
+
+var _ = pointer.Int32(1)
+`
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	rewrites := []Rewrite{
+		{Old: "k8s.io/utils/pointer", New: "k8s.io/utils/ptr"},
+		{Old: "k8s.io/utils/net", New: "k8s.io/utils/network"},
+	}
+	if !newResolver(&Options{}).rewriteImports(f, "example.go", rewrites) {
+		t.Errorf("wanted imports to be rewritten")
+	}
+	var out bytes.Buffer
+	if err := format.Node(&out, fs, f); err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, out.String())
+	}
+	if newResolver(&Options{}).rewriteImports(f, "example.go", rewrites) {
+		t.Errorf("wanted no import to be rewritten twice")
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	// SplitByModule splits groups into one sub-group per module.
+	SplitByModule = "module"
+	// SplitByOrganization splits groups into one sub-group per host, or per
+	// organization for the hosts having them, such as github.com.
+	SplitByOrganization = "organization"
+)
+
+// SplitModes lists the supported ways of splitting groups.
+var SplitModes = []string{SplitByModule, SplitByOrganization}
+
+// IsSplitMode returns whether mode is one of SplitModes.
+func IsSplitMode(mode string) bool {
+	for _, m := range SplitModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSplitGroups are the groups split when none is given.
+var DefaultSplitGroups = []string{"other"}
+
+// splitter tells the sub-group of the imports of the groups it splits.
+type splitter struct {
+	by           string
+	groups       map[string]bool
+	module       string
+	requirements []module.Version
+}
+
+// newSplitter returns the splitter configured by opts, or nil when groups are
+// not split.
+func newSplitter(opts *Options) *splitter {
+	if opts.SplitBy == "" {
+		return nil
+	}
+	s := &splitter{by: opts.SplitBy, groups: map[string]bool{}, module: opts.Module, requirements: opts.Requirements}
+	groups := opts.SplitGroups
+	if len(groups) == 0 {
+		groups = DefaultSplitGroups
+	}
+	for _, group := range groups {
+		s.groups[group] = true
+	}
+	return s
+}
+
+// key returns the sub-group of the quoted import path value in bucket, or an
+// empty string when bucket is not split. Intermediate buckets are named by
+// their patterns.
+func (s *splitter) key(r *rules, bucket, value string) string {
+	if s == nil || !(s.groups[bucket] || s.groups[r.intermediate[bucket]]) {
+		return ""
+	}
+	importPath, err := strconv.Unquote(value)
+	if err != nil {
+		return ""
+	}
+	if s.by == SplitByOrganization {
+		return organizationOf(importPath)
+	}
+	return moduleOf(importPath, s.module, s.requirements)
+}
+
+// organizationOf returns the host of importPath, followed by the organization
+// for the hosts having them.
+func organizationOf(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	if hostsWithOrganizations[elems[0]] && len(elems) > 1 {
+		return elems[0] + "/" + elems[1]
+	}
+	return elems[0]
+}
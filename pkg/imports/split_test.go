@@ -0,0 +1,128 @@
+package imports
+
+import (
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestOrganizationOf(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{importPath: "github.com/spf13/cobra", want: "github.com/spf13"},
+		{importPath: "github.com/spf13", want: "github.com/spf13"},
+		{importPath: "gopkg.in/yaml.v2", want: "gopkg.in"},
+		{importPath: "sigs.k8s.io/yaml", want: "sigs.k8s.io"},
+	}
+	for _, tt := range tests {
+		if got := organizationOf(tt.importPath); got != tt.want {
+			t.Errorf("%s: wanted %q, got %q", tt.importPath, tt.want, got)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/modfile"
+	"github.com/spf13/cobra"
+	"github.com/onsi/gomega"
+	"golang.org/x/mod/module"
+	"github.com/onsi/ginkgo/v2"
+	"gopkg.in/yaml.v2"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+`
+	tests := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{
+			name: "by module",
+			opts: &Options{
+				SplitBy:      SplitByModule,
+				Requirements: []module.Version{{Path: "github.com/onsi/ginkgo/v2"}, {Path: "golang.org/x/mod"}},
+			},
+			want: `package main
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"github.com/onsi/gomega"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spf13/viper"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+`,
+		},
+		{
+			name: "by organization",
+			opts: &Options{SplitBy: SplitByOrganization},
+			want: `package main
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+`,
+		},
+		{
+			name: "other groups",
+			opts: &Options{SplitBy: SplitByModule, SplitGroups: []string{"kubernetes"}},
+			want: `package main
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v2"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/client-go/kubernetes"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatFile(t, src, tt.opts); got != tt.want {
+			t.Errorf("%s: wanted:\n%s\ngot:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
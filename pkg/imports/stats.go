@@ -0,0 +1,171 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// Stats is an inventory of the imports of a set of files. It is safe for
+// concurrent use.
+type Stats struct {
+	mu       sync.Mutex
+	patterns []string
+
+	// Files is the number of files inventoried.
+	Files int `json:"files"`
+	// Imports is the number of imports counted.
+	Imports int `json:"imports"`
+	// Groups is the number of imports per group.
+	Groups map[string]int `json:"groups"`
+	// Modules is the number of imports per module.
+	Modules map[string]int `json:"modules"`
+	// Packages lists the files importing each package, sorted.
+	Packages map[string][]string `json:"packages"`
+}
+
+// NewStats returns an empty inventory counting the imports matching one of
+// patterns, or all imports when there is none. A pattern is either an import
+// path or an import path followed by /... to match the packages under it.
+func NewStats(patterns []string) *Stats {
+	return &Stats{
+		patterns: patterns,
+		Groups:   map[string]int{},
+		Modules:  map[string]int{},
+		Packages: map[string][]string{},
+	}
+}
+
+// CollectStats takes a channel of file paths and counts the files imports
+// into stats according to opts, or the options of their directories when opts
+// has ForDir, and the overrides applying to each file. The files received
+// once ctx is done are not counted, nor the generated and ignored ones that
+// organizing imports skips.
+func CollectStats(ctx context.Context, files chan string, wg *sync.WaitGroup, opts *Options, stats *Stats) {
+	defer wg.Done()
+	formatters := map[*Options]*formatter{}
+
+	for path := range files {
+		if len(path) == 0 || ctx.Err() != nil {
+			continue
+		}
+		klog.V(2).Infof("Counting %s", path)
+		fm, err := formatterFor(formatters, opts, path)
+		if err != nil {
+			opts.Reporter.Report(path, 0, fmt.Sprintf("not counted: %v", err))
+			continue
+		}
+		fileRules, err := fm.overrides.forFile(path)
+		if err != nil {
+			fm.opts.Reporter.Report(path, 0, fmt.Sprintf("not counted: %v", err))
+			continue
+		}
+		r, place := fileRules.rules, fileRules.place
+
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			klog.Errorf("%v", err)
+			continue
+		}
+		if !fm.opts.IncludeGenerated && isGenerated(f) {
+			klog.V(2).Infof("Skipping generated file %s", path)
+			continue
+		}
+		if isIgnored(f) {
+			klog.V(2).Infof("Skipping %s, ignored by %s", path, IgnoreDirective)
+			continue
+		}
+
+		stats.mu.Lock()
+		stats.Files++
+		for _, i := range f.Imports {
+			importPath, err := strconv.Unquote(i.Path.Value)
+			if err != nil || !stats.matches(importPath) {
+				continue
+			}
+			stats.Imports++
+			stats.Groups[r.describe(place.bucket(r, i))]++
+			stats.Modules[moduleOf(importPath, fm.opts.Module, fm.opts.Requirements)]++
+			stats.Packages[importPath] = insertSorted(stats.Packages[importPath], path)
+		}
+		stats.mu.Unlock()
+	}
+}
+
+// matches returns whether importPath is counted.
+func (s *Stats) matches(importPath string) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range s.patterns {
+		if matchPackage(importPath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count is a name and how many times it was counted.
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Sorted returns the counts of m, the highest first and then by name.
+func Sorted(m map[string]int) []Count {
+	var counts []Count
+	for name, count := range m {
+		counts = append(counts, Count{Name: name, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// PackageCounts returns the number of files importing each package.
+func (s *Stats) PackageCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := map[string]int{}
+	for pkg, files := range s.Packages {
+		counts[pkg] = len(files)
+	}
+	return counts
+}
+
+// insertSorted inserts s into the sorted list unless it is already there.
+func insertSorted(list []string, s string) []string {
+	i := sort.SearchStrings(list, s)
+	if i < len(list) && list[i] == s {
+		return list
+	}
+	list = append(list, "")
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
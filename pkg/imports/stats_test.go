@@ -0,0 +1,235 @@
+package imports
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCollectStats(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "tools-test")
+	if err != nil {
+		t.Fatalf("Failed to make temporary directory: %s", err)
+	}
+	defer os.RemoveAll(testDir)
+
+	sources := map[string]string{
+		"a.go": `package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"k8s.io/klog/v2"
+)
+`,
+		"b.go": `package main
+
+import (
+	"io/ioutil"
+
+	"example.com/exampkg/util"
+)
+`,
+	}
+	var paths []string
+	for name, src := range sources {
+		path := filepath.Join(testDir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     *Stats
+	}{
+		{
+			name: "all imports",
+			want: &Stats{
+				Files:   2,
+				Imports: 5,
+				Groups:  map[string]int{"standard": 3, "kubernetes": 1, "module": 1},
+				Modules: map[string]int{StandardModule: 3, "k8s.io/klog/v2": 1, "example.com/exampkg": 1},
+				Packages: map[string][]string{
+					"io/ioutil":                {filepath.Join(testDir, "a.go"), filepath.Join(testDir, "b.go")},
+					"os":                       {filepath.Join(testDir, "a.go")},
+					"k8s.io/klog/v2":           {filepath.Join(testDir, "a.go")},
+					"example.com/exampkg/util": {filepath.Join(testDir, "b.go")},
+				},
+			},
+		},
+		{
+			name:     "filtered imports",
+			patterns: []string{"io/ioutil", "example.com/..."},
+			want: &Stats{
+				Files:   2,
+				Imports: 3,
+				Groups:  map[string]int{"standard": 2, "module": 1},
+				Modules: map[string]int{StandardModule: 2, "example.com/exampkg": 1},
+				Packages: map[string][]string{
+					"io/ioutil":                {filepath.Join(testDir, "a.go"), filepath.Join(testDir, "b.go")},
+					"example.com/exampkg/util": {filepath.Join(testDir, "b.go")},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		stats := NewStats(test.patterns)
+		files := make(chan string, len(paths))
+		for _, path := range paths {
+			files <- path
+		}
+		close(files)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		CollectStats(context.Background(), files, &wg, &Options{Module: "example.com/exampkg"}, stats)
+
+		if stats.Files != test.want.Files || stats.Imports != test.want.Imports ||
+			!reflect.DeepEqual(stats.Groups, test.want.Groups) ||
+			!reflect.DeepEqual(stats.Modules, test.want.Modules) ||
+			!reflect.DeepEqual(stats.Packages, test.want.Packages) {
+			t.Errorf("test: %s, wanted: %#v, got %#v", test.name, test.want, stats)
+		}
+	}
+}
+
+func TestCollectStatsPerFile(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"a_test.go": `package main
+
+import (
+	_ "embed"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+`,
+		"staging/api/b.go": `package api
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+`,
+	}
+	var paths []string
+	for name, src := range sources {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	opts := &Options{
+		Module:       "example.com/exampkg",
+		ModuleDir:    dir,
+		BlankImports: PlacementGroup,
+		Overrides: []Override{{
+			Files:  []string{"*_test.go"},
+			Groups: []Group{{Name: "testing", Patterns: []string{"github.com/onsi/"}}},
+		}},
+	}
+	staging := &Options{Module: "k8s.io/api", ModuleDir: filepath.Join(dir, "staging", "api")}
+	opts.ForDir = func(d string) (*Options, error) {
+		if d == staging.ModuleDir {
+			return staging, nil
+		}
+		return opts, nil
+	}
+
+	collect := func(ctx context.Context) *Stats {
+		stats := NewStats(nil)
+		files := make(chan string, len(paths))
+		for _, path := range paths {
+			files <- path
+		}
+		close(files)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		CollectStats(ctx, files, &wg, opts, stats)
+		return stats
+	}
+
+	want := map[string]int{BlankGroup: 1, "standard": 1, "testing": 1, "module": 1, "kubernetes": 1}
+	if stats := collect(context.Background()); !reflect.DeepEqual(stats.Groups, want) {
+		t.Errorf("wanted groups %v, got %v", want, stats.Groups)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if stats := collect(ctx); stats.Files != 0 || stats.Imports != 0 {
+		t.Errorf("wanted nothing counted once cancelled, got %d files and %d imports", stats.Files, stats.Imports)
+	}
+}
+
+func TestCollectStatsSkipped(t *testing.T) {
+	sources := map[string]string{
+		"a.go": `package main
+
+import "os"
+`,
+		"zz_generated.deepcopy.go": `// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package main
+
+import "k8s.io/apimachinery/pkg/runtime"
+`,
+		"ignored.go": `//openshift-goimports:ignore
+
+package main
+
+import "fmt"
+`,
+	}
+	dir := t.TempDir()
+	var paths []string
+	for name, src := range sources {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	tests := []struct {
+		name             string
+		includeGenerated bool
+		want             map[string]int
+	}{
+		{
+			name: "generated and ignored files",
+			want: map[string]int{StandardModule: 1},
+		},
+		{
+			name:             "included generated files",
+			includeGenerated: true,
+			want:             map[string]int{StandardModule: 1, "k8s.io/apimachinery": 1},
+		},
+	}
+	for _, test := range tests {
+		stats := NewStats(nil)
+		files := make(chan string, len(paths))
+		for _, path := range paths {
+			files <- path
+		}
+		close(files)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		CollectStats(context.Background(), files, &wg, &Options{Module: "example.com/exampkg", IncludeGenerated: test.includeGenerated}, stats)
+
+		if stats.Files != len(test.want) || !reflect.DeepEqual(stats.Modules, test.want) {
+			t.Errorf("test: %s, wanted %d files importing %v, got %d files importing %v", test.name, len(test.want), test.want, stats.Files, stats.Modules)
+		}
+	}
+}
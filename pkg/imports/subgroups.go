@@ -0,0 +1,88 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// subgroup identifies the sub-group of an import within its group: the
+// blank or dot imports placed first or last, a preserved sub-group of the
+// file, then a sub-group of a split group.
+type subgroup struct {
+	rank      int
+	preserved int
+	split     string
+}
+
+// less returns whether the imports of s come before the ones of other.
+func (s subgroup) less(other subgroup) bool {
+	if s.rank != other.rank {
+		return s.rank < other.rank
+	}
+	if s.preserved != other.preserved {
+		return s.preserved < other.preserved
+	}
+	return s.split < other.split
+}
+
+// subgroups returns the sub-group of each import of f in a run of imports
+// separated by blank lines whose imports all belong to the same group, the
+// blank and dot imports being in the group place puts them in, numbered from
+// 1 in the order of the file. The other imports, the frozen ones, and the
+// ones added since f was parsed are left out.
+func (r *rules) subgroups(fs *token.FileSet, f *ast.File, frozen map[*ast.ImportSpec]bool, place placement) map[*ast.BasicLit]int {
+	subgroups := map[*ast.BasicLit]int{}
+	file := fs.File(f.Pos())
+	next := 1
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var run []*ast.ImportSpec
+		flush := func() {
+			for _, i := range run {
+				if place.bucket(r, i) != place.bucket(r, run[0]) {
+					run = nil
+					return
+				}
+			}
+			for _, i := range run {
+				subgroups[i.Path] = next
+			}
+			if len(run) > 0 {
+				next++
+			}
+			run = nil
+		}
+		for _, spec := range gen.Specs {
+			i := spec.(*ast.ImportSpec)
+			if frozen[i] || !i.Pos().IsValid() {
+				flush()
+				continue
+			}
+			if len(run) > 0 && hasBlankLine(f, file, file.Line(run[len(run)-1].Pos()), file.Line(i.Pos())) {
+				flush()
+			}
+			run = append(run, i)
+		}
+		flush()
+	}
+	return subgroups
+}
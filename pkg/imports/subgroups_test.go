@@ -0,0 +1,144 @@
+package imports
+
+import (
+	"testing"
+)
+
+func TestPreserveSubgroups(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "sub-groups sorted inside",
+			src: `package main
+
+import (
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/apps/v1"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/kubernetes"
+)
+`,
+			want: `package main
+
+import (
+	"fmt"
+
+	"k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+`,
+		},
+		{
+			name: "mixed runs are split into groups",
+			src: `package main
+
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"os"
+	"k8s.io/api/core/v1"
+	"github.com/spf13/cobra"
+)
+`,
+			want: `package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+`,
+		},
+		{
+			name: "sub-groups keep the order of the file",
+			src: `package main
+
+import (
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/api/core/v1"
+
+	"fmt"
+)
+`,
+			want: `package main
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/api/core/v1"
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatFile(t, tt.src, &Options{PreserveSubgroups: true}); got != tt.want {
+			t.Errorf("%s: wanted:\n%s\ngot:\n%s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSubgroupsDisabled(t *testing.T) {
+	src := `package main
+
+import (
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/api/core/v1"
+)
+`
+	want := `package main
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+`
+	if got := formatFile(t, src, &Options{}); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestPreserveSubgroupsPlacement(t *testing.T) {
+	src := `package main
+
+import (
+	"os"
+	"github.com/spf13/cobra"
+
+	"fmt"
+	_ "embed"
+)
+`
+	want := `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	_ "embed"
+)
+`
+	if got := formatFile(t, src, &Options{PreserveSubgroups: true, BlankImports: PlacementGroup}); got != want {
+		t.Errorf("wanted:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package imports
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// verifyOutput checks that out, the organized source of f, only differs from
+// f in the order, grouping and layout of its imports: out must declare the
+// same package, import the same packages under the same names, and hold the
+// same other declarations, in the same order.
+func verifyOutput(f *ast.File, out []byte) error {
+	g, err := parser.ParseFile(token.NewFileSet(), "", out, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("the result does not parse: %v", err)
+	}
+	if f.Name.Name != g.Name.Name {
+		return fmt.Errorf("the package name changed from %s to %s", f.Name.Name, g.Name.Name)
+	}
+
+	want, got := importList(f), importList(g)
+	for n := 0; n < len(want) || n < len(got); n++ {
+		switch {
+		case n >= len(got) || (n < len(want) && want[n] < got[n]):
+			return fmt.Errorf("import %s is missing from the result", want[n])
+		case n >= len(want) || want[n] > got[n]:
+			return fmt.Errorf("import %s was added to the result", got[n])
+		}
+	}
+
+	wantDecls, gotDecls := otherDecls(f), otherDecls(g)
+	if len(wantDecls) != len(gotDecls) {
+		return fmt.Errorf("the result has %d declarations besides the imports instead of %d", len(gotDecls), len(wantDecls))
+	}
+	for n := range wantDecls {
+		if !equalNodes(reflect.ValueOf(wantDecls[n]), reflect.ValueOf(gotDecls[n])) {
+			return fmt.Errorf("declaration %d besides the imports changed", n+1)
+		}
+	}
+	return nil
+}
+
+// importList returns the sorted imports of f, each as its name, if any,
+// followed by its quoted path.
+func importList(f *ast.File) []string {
+	var list []string
+	for _, i := range f.Imports {
+		if i.Name != nil {
+			list = append(list, i.Name.Name+" "+i.Path.Value)
+		} else {
+			list = append(list, i.Path.Value)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// otherDecls returns the declarations of f that are not import declarations.
+func otherDecls(f *ast.File) []ast.Decl {
+	var decls []ast.Decl
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		decls = append(decls, decl)
+	}
+	return decls
+}
+
+var (
+	posType      = reflect.TypeOf(token.NoPos)
+	objectType   = reflect.TypeOf((*ast.Object)(nil))
+	scopeType    = reflect.TypeOf((*ast.Scope)(nil))
+	commentsType = reflect.TypeOf((*ast.CommentGroup)(nil))
+	basicLitType = reflect.TypeOf(ast.BasicLit{})
+)
+
+// equalNodes returns whether the syntax trees a and b are the same, apart
+// from their positions, comments and resolved objects. Number literals are
+// compared regardless of case, gofmt normalizing their prefixes and
+// exponents.
+func equalNodes(a, b reflect.Value) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Type() {
+	case posType, objectType, scopeType, commentsType:
+		return true
+	case basicLitType:
+		x, y := a.Interface().(ast.BasicLit), b.Interface().(ast.BasicLit)
+		if x.Kind != y.Kind {
+			return false
+		}
+		if x.Kind == token.INT || x.Kind == token.FLOAT || x.Kind == token.IMAG {
+			return strings.EqualFold(x.Value, y.Value)
+		}
+		return x.Value == y.Value
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalNodes(a.Elem(), b.Elem())
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for n := 0; n < a.Len(); n++ {
+			if !equalNodes(a.Index(n), b.Index(n)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for n := 0; n < a.NumField(); n++ {
+			if a.Type().Field(n).PkgPath != "" {
+				continue
+			}
+			if !equalNodes(a.Field(n), b.Field(n)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
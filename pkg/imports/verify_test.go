@@ -0,0 +1,152 @@
+package imports
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestVerifyOutput(t *testing.T) {
+	src := `package main
+
+import "os"
+
+import (
+	"fmt"
+	log "k8s.io/klog/v2"
+)
+
+const mask = 0XFF
+
+func main() {
+	// print the arguments
+	fmt.Println(os.Args, mask)
+	log.Info("done")
+}
+`
+	tests := []struct {
+		name    string
+		out     string
+		wantErr string
+	}{
+		{
+			name: "imports organized",
+			out: `package main
+
+import (
+	"fmt"
+	"os"
+
+	log "k8s.io/klog/v2"
+)
+
+const mask = 0xFF
+
+func main() {
+	fmt.Println(os.Args, mask) // print the arguments
+	log.Info("done")
+}
+`,
+		},
+		{
+			name: "missing import",
+			out: `package main
+
+import (
+	"fmt"
+
+	log "k8s.io/klog/v2"
+)
+
+const mask = 0XFF
+
+func main() {
+	fmt.Println(os.Args, mask)
+	log.Info("done")
+}
+`,
+			wantErr: `import "os" is missing from the result`,
+		},
+		{
+			name: "renamed import",
+			out: `package main
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+)
+
+const mask = 0XFF
+
+func main() {
+	fmt.Println(os.Args, mask)
+	log.Info("done")
+}
+`,
+			wantErr: `import "k8s.io/klog/v2" was added to the result`,
+		},
+		{
+			name: "changed declaration",
+			out: `package main
+
+import (
+	"fmt"
+	"os"
+
+	log "k8s.io/klog/v2"
+)
+
+const mask = 0XFF
+
+func main() {
+	fmt.Println(os.Args, mask)
+}
+`,
+			wantErr: "declaration 2 besides the imports changed",
+		},
+		{
+			name: "repeated declaration",
+			out: `package main
+
+import (
+	"fmt"
+	"os"
+
+	log "k8s.io/klog/v2"
+)
+
+const mask = 0XFF
+
+const mask = 0XFF
+
+func main() {
+	fmt.Println(os.Args, mask)
+	log.Info("done")
+}
+`,
+			wantErr: "the result has 3 declarations besides the imports instead of 2",
+		},
+		{
+			name:    "broken result",
+			out:     "package main\n\nimport (\n",
+			wantErr: "the result does not parse: ",
+		},
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "example.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		err := verifyOutput(f, []byte(test.out))
+		switch {
+		case err == nil && test.wantErr != "":
+			t.Errorf("test: %s, wanted error %q", test.name, test.wantErr)
+		case err != nil && (test.wantErr == "" || !strings.HasPrefix(err.Error(), test.wantErr)):
+			t.Errorf("test: %s, wanted error %q, got %q", test.name, test.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the name of the files whose patterns exclude paths from the
+// walk, with the semantics of git.
+const IgnoreFile = ".gitignore"
+
+// Pattern is a path pattern with the syntax of the lines of a .gitignore
+// file, relative to the directory Base.
+type Pattern struct {
+	Base    string
+	Negate  bool
+	DirOnly bool
+
+	re *regexp.Regexp
+}
+
+// ParsePattern parses the .gitignore line s, relative to base. It returns nil
+// for blank lines and comments.
+func ParsePattern(base, s string) (*Pattern, error) {
+	s = strings.TrimRight(s, "\r")
+	if !strings.HasSuffix(s, `\ `) {
+		s = strings.TrimRight(s, " ")
+	}
+	if len(s) == 0 || strings.HasPrefix(s, "#") {
+		return nil, nil
+	}
+
+	p := &Pattern{Base: base}
+	switch {
+	case strings.HasPrefix(s, "!"):
+		p.Negate = true
+		s = s[1:]
+	case strings.HasPrefix(s, `\!`), strings.HasPrefix(s, `\#`):
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "/") {
+		p.DirOnly = true
+		s = strings.TrimRight(s, "/")
+	}
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	// A pattern with a slash anywhere but at its end only matches relative to
+	// its base, otherwise it matches a name at any depth.
+	prefix := "^(?:.*/)?"
+	if strings.Contains(s, "/") {
+		prefix = "^"
+		s = strings.TrimPrefix(s, "/")
+	}
+	expr, err := translate(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", s, err)
+	}
+	if p.re, err = regexp.Compile(prefix + expr + "$"); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", s, err)
+	}
+	return p, nil
+}
+
+// translate returns the regular expression matching the glob s, where * and ?
+// do not match slashes and ** matches any number of directories.
+func translate(s string) (string, error) {
+	var b strings.Builder
+	for n := 0; n < len(s); n++ {
+		c := s[n]
+		switch {
+		case strings.HasPrefix(s[n:], "**/") && (n == 0 || s[n-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			n += 2
+		case strings.HasPrefix(s[n:], "**") && n+2 == len(s) && (n == 0 || s[n-1] == '/'):
+			b.WriteString(".*")
+			n++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(s[n+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := s[n+1 : n+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			n += end + 1
+		case c == '\\' && n+1 < len(s):
+			n++
+			b.WriteString(regexp.QuoteMeta(string(s[n])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// Match returns whether the pattern matches path, a slash separated path
+// relative to the base of the pattern.
+func (p *Pattern) Match(path string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+	return p.re.MatchString(path)
+}
+
+// ReadIgnoreFile returns the patterns of the .gitignore file of dir, if any.
+func ReadIgnoreFile(dir string) ([]*Pattern, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []*Pattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		p, err := ParsePattern(dir, scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name(), err)
+		}
+		if p != nil {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// Ignored returns whether the last of patterns matching path, an absolute
+// path, excludes it.
+func Ignored(patterns []*Pattern, path string, isDir bool) bool {
+	ignored := false
+	for _, p := range patterns {
+		rel, err := filepath.Rel(p.Base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if p.Match(filepath.ToSlash(rel), isDir) {
+			ignored = !p.Negate
+		}
+	}
+	return ignored
+}
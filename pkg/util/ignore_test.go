@@ -0,0 +1,64 @@
+package util
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	base := filepath.FromSlash("/repo")
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		ignored  bool
+	}{
+		{name: "name at any depth", patterns: []string{"*.pb.go"}, path: "pkg/api/types.pb.go", ignored: true},
+		{name: "name not matching", patterns: []string{"*.pb.go"}, path: "pkg/api/types.go", ignored: false},
+		{name: "anchored", patterns: []string{"/build"}, path: "build", isDir: true, ignored: true},
+		{name: "anchored at another depth", patterns: []string{"/build"}, path: "pkg/build", isDir: true, ignored: false},
+		{name: "middle slash anchors", patterns: []string{"pkg/gen"}, path: "cmd/pkg/gen", isDir: true, ignored: false},
+		{name: "directory only", patterns: []string{"output/"}, path: "output", isDir: true, ignored: true},
+		{name: "directory only on file", patterns: []string{"output/"}, path: "output", ignored: false},
+		{name: "leading double star", patterns: []string{"**/zz_generated.go"}, path: "a/b/zz_generated.go", ignored: true},
+		{name: "leading double star at base", patterns: []string{"**/zz_generated.go"}, path: "zz_generated.go", ignored: true},
+		{name: "trailing double star", patterns: []string{"hack/**"}, path: "hack/tools/tools.go", ignored: true},
+		{name: "middle double star", patterns: []string{"a/**/b.go"}, path: "a/x/y/b.go", ignored: true},
+		{name: "middle double star without directories", patterns: []string{"a/**/b.go"}, path: "a/b.go", ignored: true},
+		{name: "star does not match slash", patterns: []string{"/a/*.go"}, path: "a/b/c.go", ignored: false},
+		{name: "question mark", patterns: []string{"v?.go"}, path: "v1.go", ignored: true},
+		{name: "character class", patterns: []string{"v[0-9].go"}, path: "vx.go", ignored: false},
+		{name: "negated character class", patterns: []string{"v[!0-9].go"}, path: "vx.go", ignored: true},
+		{name: "negation", patterns: []string{"*.go", "!main.go"}, path: "main.go", ignored: false},
+		{name: "negation then ignore", patterns: []string{"!main.go", "*.go"}, path: "main.go", ignored: true},
+		{name: "comment", patterns: []string{"# main.go"}, path: "main.go", ignored: false},
+		{name: "escaped hash", patterns: []string{`\#main.go`}, path: "#main.go", ignored: true},
+		{name: "escaped bang", patterns: []string{`\!main.go`}, path: "!main.go", ignored: true},
+	}
+	for _, tt := range tests {
+		var patterns []*Pattern
+		for _, s := range tt.patterns {
+			p, err := ParsePattern(base, s)
+			if err != nil {
+				t.Fatalf("%s: failed to parse %q: %v", tt.name, s, err)
+			}
+			if p != nil {
+				patterns = append(patterns, p)
+			}
+		}
+		if got := Ignored(patterns, filepath.Join(base, filepath.FromSlash(tt.path)), tt.isDir); got != tt.ignored {
+			t.Errorf("%s: wanted %v, got %v", tt.name, tt.ignored, got)
+		}
+	}
+}
+
+func TestIgnoredOutsideBase(t *testing.T) {
+	p, err := ParsePattern(filepath.FromSlash("/repo/pkg"), "*.go")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if Ignored([]*Pattern{p}, filepath.FromSlash("/repo/cmd/main.go"), false) {
+		t.Errorf("wanted a pattern not to apply outside of its base")
+	}
+}
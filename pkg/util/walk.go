@@ -0,0 +1,175 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Walker walks the go files of a tree. It skips the vendor and testdata
+// directories, the directories whose names start with a dot or an
+// underscore, the paths ignored by the .gitignore files of the tree and of
+// the repository holding it, and the paths matching its exclude patterns.
+// When it has include patterns, only the files matching one of them are
+// walked.
+type Walker struct {
+	path    string
+	root    string
+	top     string
+	exclude []*Pattern
+	include []*Pattern
+	ignores map[string][]*Pattern
+}
+
+// NewWalker returns a walker of the tree at path, with exclude and include
+// patterns in the .gitignore syntax, relative to path.
+func NewWalker(path string, exclude, include []string) (*Walker, error) {
+	root, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Walker{path: path, root: root, top: root, ignores: map[string][]*Pattern{}}
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if isRepositoryRoot(dir) {
+			w.top = dir
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if w.exclude, err = parsePatterns(root, exclude); err != nil {
+		return nil, err
+	}
+	if w.include, err = parsePatterns(root, include); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func parsePatterns(base string, patterns []string) ([]*Pattern, error) {
+	var parsed []*Pattern
+	for _, s := range patterns {
+		p, err := ParsePattern(base, s)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			parsed = append(parsed, p)
+		}
+	}
+	return parsed, nil
+}
+
+// Walk calls fn with the path of each go file of the tree, as joined to the
+// path given to NewWalker. It stops when ctx is done, returning its error.
+func (w *Walker) Walk(ctx context.Context, fn func(path string)) error {
+	return filepath.Walk(w.path, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			if abs != w.root && skipDir(f.Name()) {
+				return filepath.SkipDir
+			}
+			if skip, err := w.skip(abs, true); err != nil || skip {
+				if err == nil {
+					err = filepath.SkipDir
+				}
+				return err
+			}
+			return nil
+		}
+		if !IsGoFile(f) {
+			return nil
+		}
+		if skip, err := w.skip(abs, false); err != nil || skip {
+			return err
+		}
+		if len(w.include) > 0 && !matchesAny(w.include, abs) {
+			return nil
+		}
+		fn(path)
+		return nil
+	})
+}
+
+// skipDir returns whether the directory named name is skipped whatever the
+// patterns are, like the go tool does.
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// skip returns whether the path abs is ignored or excluded.
+func (w *Walker) skip(abs string, isDir bool) (bool, error) {
+	if abs == w.root {
+		return false, nil
+	}
+	ignores, err := w.ignoresOf(filepath.Dir(abs))
+	if err != nil {
+		return false, err
+	}
+	return Ignored(ignores, abs, isDir) || Ignored(w.exclude, abs, isDir), nil
+}
+
+// ignoresOf returns the .gitignore patterns applying to the paths in dir,
+// from the root of the repository, or of the tree when it is not in one, down
+// to dir.
+func (w *Walker) ignoresOf(dir string) ([]*Pattern, error) {
+	if ignores, ok := w.ignores[dir]; ok {
+		return ignores, nil
+	}
+	var ignores []*Pattern
+	if parent := filepath.Dir(dir); dir != w.top && parent != dir {
+		var err error
+		if ignores, err = w.ignoresOf(parent); err != nil {
+			return nil, err
+		}
+	}
+	own, err := ReadIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	ignores = append(ignores[:len(ignores):len(ignores)], own...)
+	w.ignores[dir] = ignores
+	return ignores, nil
+}
+
+// isRepositoryRoot returns whether dir is the top directory of a git
+// repository.
+func isRepositoryRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+func matchesAny(patterns []*Pattern, abs string) bool {
+	for _, p := range patterns {
+		if Ignored([]*Pattern{p}, abs, false) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWalker(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":               "",
+		".gitignore":              "/build/\nzz_generated.*.go\n",
+		"main.go":                 "",
+		"main_test.go":            "",
+		".hidden/a.go":            "",
+		"_output/a.go":            "",
+		"testdata/a.go":           "",
+		"vendor/a/a.go":           "",
+		"build/a.go":              "",
+		"pkg/a/a.go":              "",
+		"pkg/a/zz_generated.a.go": "",
+		"pkg/b/.gitignore":        "*.go\n!b.go\n",
+		"pkg/b/b.go":              "",
+		"pkg/b/c.go":              "",
+		"test/e2e/e2e.go":         "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		exclude []string
+		include []string
+		want    []string
+	}{
+		{
+			name: "defaults",
+			path: ".",
+			want: []string{"main.go", "main_test.go", "pkg/a/a.go", "pkg/b/b.go", "test/e2e/e2e.go"},
+		},
+		{
+			name:    "exclude",
+			path:    ".",
+			exclude: []string{"/test/e2e/", "*_test.go"},
+			want:    []string{"main.go", "pkg/a/a.go", "pkg/b/b.go"},
+		},
+		{
+			name:    "include",
+			path:    ".",
+			include: []string{"pkg/**"},
+			want:    []string{"pkg/a/a.go", "pkg/b/b.go"},
+		},
+		{
+			name: "repository ignore files apply to a subdirectory",
+			path: "pkg",
+			want: []string{"pkg/a/a.go", "pkg/b/b.go"},
+		},
+		{
+			name: "skipped directory given as path",
+			path: "testdata",
+			want: []string{"testdata/a.go"},
+		},
+	}
+	for _, tt := range tests {
+		w, err := NewWalker(filepath.Join(root, tt.path), tt.exclude, tt.include)
+		if err != nil {
+			t.Fatalf("%s: failed to create walker: %v", tt.name, err)
+		}
+		var got []string
+		err = w.Walk(context.Background(), func(path string) {
+			rel, _ := filepath.Rel(root, path)
+			got = append(got, filepath.ToSlash(rel))
+		})
+		if err != nil {
+			t.Fatalf("%s: failed to walk: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wanted %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWalkerCancel(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := NewWalker(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []string
+	err = w.Walk(ctx, func(path string) {
+		got = append(got, filepath.Base(path))
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted the walk to be cancelled, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a.go"}) {
+		t.Errorf("wanted the walk to stop after a.go, got %v", got)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 Corey Daley <cdaley@redhat.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package util
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrChanged is returned by WriteFile when the file changed since it was read.
+var ErrChanged = errors.New("file changed since it was read")
+
+// WriteFile replaces the contents of the file at path, or of the file it
+// links to, with out, unless they changed since they were read as read. out
+// is written to a temporary file of the same directory renamed over the file,
+// so the file is never left partially written, with the mode of the file and,
+// when permitted, its owner.
+func WriteFile(path string, read, out []byte) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	current, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	if sha256.Sum256(current) != sha256.Sum256(read) {
+		return ErrChanged
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// chown may clear the setuid and setgid bits, so it goes first
+	chown(tmp.Name(), info)
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	renamed = true
+	return nil
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(path, []byte("old"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.go")
+	if err := os.Symlink("a.go", link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		read    string
+		out     string
+		want    string
+		wantErr error
+	}{
+		{name: "file", path: path, read: "old", out: "new", want: "new"},
+		{name: "symlink", path: link, read: "new", out: "newer", want: "newer"},
+		{name: "changed since read", path: path, read: "new", out: "newest", want: "newer", wantErr: ErrChanged},
+	}
+	for _, test := range tests {
+		if err := WriteFile(test.path, []byte(test.read), []byte(test.out)); !errors.Is(err, test.wantErr) {
+			t.Fatalf("test: %s, wanted error %v, got %v", test.name, test.wantErr, err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != test.want {
+			t.Errorf("test: %s, wanted %q, got %q, %v", test.name, test.want, got, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0640 {
+			t.Errorf("test: %s, wanted mode 0640, got %v, %v", test.name, info.Mode(), err)
+		}
+		if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("test: %s, wanted %s to stay a symlink, got %v, %v", test.name, link, info.Mode(), err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("wanted no temporary file to be left, got %d files", len(entries))
+	}
+}